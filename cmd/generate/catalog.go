@@ -0,0 +1,381 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var catalogOutput string
+
+// ListCmd lists the available templates
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the available templates",
+	Long: `List every template found in --template-dir, in the directories listed
+under templates.paths in the config file, and in the templates built into iron.
+
+When several sources hold a template with the same name, the first one is used
+by iron generate and the others are marked as shadowed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCatalogOutput(); err != nil {
+			return err
+		}
+
+		sources, err := Sources(templateDirs())
+		if err != nil {
+			return err
+		}
+
+		summaries, err := ListTemplates(sources)
+		if err != nil {
+			return err
+		}
+
+		if catalogOutput == OutputJSON {
+			return printJSON(os.Stdout, summaries)
+		}
+		printTemplateList(os.Stdout, summaries)
+		return nil
+	},
+}
+
+// InfoCmd describes a template in detail
+var InfoCmd = &cobra.Command{
+	Use:   "info <name>",
+	Short: "Show the variables, features, hooks and files of a template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCatalogOutput(); err != nil {
+			return err
+		}
+
+		sources, err := Sources(templateDirs())
+		if err != nil {
+			return err
+		}
+
+		tmpl, err := ResolveTemplate(args[0], sources)
+		if err != nil {
+			return err
+		}
+
+		info, err := DescribeTemplate(tmpl)
+		if err != nil {
+			return err
+		}
+
+		if catalogOutput == OutputJSON {
+			return printJSON(os.Stdout, info)
+		}
+		printTemplateInfo(os.Stdout, info)
+		return nil
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{ListCmd, InfoCmd} {
+		cmd.Flags().StringVar(&templateDir, "template-dir", "", "Directory to load templates from before the configured and built-in ones")
+		cmd.Flags().StringVarP(&catalogOutput, "output", "o", OutputText, "Output format: text or json")
+		TemplateCmd.AddCommand(cmd)
+	}
+}
+
+// validateCatalogOutput checks the --output flag of the list and info commands
+func validateCatalogOutput() error {
+	if catalogOutput != OutputText && catalogOutput != OutputJSON {
+		return fmt.Errorf("invalid output format '%s' (valid formats: %s, %s)", catalogOutput, OutputText, OutputJSON)
+	}
+	return nil
+}
+
+// TemplateSummary is a template as shown by iron template list
+type TemplateSummary struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	// ShadowedBy is the source of the template with the same name that takes precedence
+	ShadowedBy string `json:"shadowedBy,omitempty"`
+	// Error is set when the manifest of the template cannot be loaded
+	Error string `json:"error,omitempty"`
+}
+
+// ListTemplates describes every template of every source, in precedence order
+func ListTemplates(sources []Source) ([]TemplateSummary, error) {
+	summaries := []TemplateSummary{}
+	seen := map[string]string{}
+
+	for _, source := range sources {
+		entries, err := fs.ReadDir(source.FS, ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates in '%s': %w", source.Name, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			templateFS, err := fs.Sub(source.FS, entry.Name())
+			if err != nil {
+				return nil, fmt.Errorf("failed to open template '%s': %w", entry.Name(), err)
+			}
+
+			summary := TemplateSummary{Name: entry.Name(), Source: source.Name, ShadowedBy: seen[entry.Name()]}
+			if _, ok := seen[entry.Name()]; !ok {
+				seen[entry.Name()] = source.Name
+			}
+
+			manifest, err := LoadManifest(&Template{Name: entry.Name(), Source: source.Name, FS: templateFS})
+			if err != nil {
+				summary.Error = err.Error()
+			} else {
+				summary.Version = manifest.Version
+				summary.Description, _, _ = strings.Cut(strings.TrimSpace(manifest.Description), "\n")
+			}
+
+			summaries = append(summaries, summary)
+		}
+	}
+
+	return summaries, nil
+}
+
+// printTemplateList prints the templates as a table
+func printTemplateList(w io.Writer, summaries []TemplateSummary) {
+	if len(summaries) == 0 {
+		_, _ = fmt.Fprintln(w, "No templates found")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tVERSION\tSOURCE\tDESCRIPTION")
+	for _, s := range summaries {
+		description := s.Description
+		switch {
+		case s.Error != "":
+			description = "(invalid manifest)"
+		case s.ShadowedBy != "":
+			description += " (shadowed by " + s.ShadowedBy + ")"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, orDash(s.Version), s.Source, description)
+	}
+	_ = tw.Flush()
+}
+
+// TemplateInfo is a template as shown by iron template info
+type TemplateInfo struct {
+	Name        string          `json:"name"`
+	Source      string          `json:"source"`
+	Version     string          `json:"version,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Variables   []VariableInfo  `json:"variables"`
+	Features    []FeatureInfo   `json:"features"`
+	Hooks       []HookInfo      `json:"hooks"`
+	Tools       []ToolInfo      `json:"tools"`
+	Files       []TemplateEntry `json:"files"`
+}
+
+// VariableInfo describes a template variable
+type VariableInfo struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Default  *string  `json:"default,omitempty"`
+	Required bool     `json:"required"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Choices  []string `json:"choices,omitempty"`
+	Help     string   `json:"help,omitempty"`
+}
+
+// FeatureInfo describes an optional template feature
+type FeatureInfo struct {
+	Name    string   `json:"name"`
+	Default bool     `json:"default"`
+	Paths   []string `json:"paths,omitempty"`
+	Help    string   `json:"help,omitempty"`
+}
+
+// HookInfo describes a post-generation hook
+type HookInfo struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+	Timeout string   `json:"timeout"`
+	Feature string   `json:"feature,omitempty"`
+}
+
+// ToolInfo is a program the hooks of a template need
+type ToolInfo struct {
+	Name      string `json:"name"`
+	Install   string `json:"install,omitempty"`
+	Installed bool   `json:"installed"`
+}
+
+// TemplateEntry is a file of a template
+type TemplateEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// DescribeTemplate collects everything iron template info shows about tmpl
+func DescribeTemplate(tmpl *Template) (*TemplateInfo, error) {
+	manifest, err := LoadManifest(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &TemplateInfo{
+		Name:        tmpl.Name,
+		Source:      tmpl.Source,
+		Version:     manifest.Version,
+		Description: strings.TrimSpace(manifest.Description),
+		Variables:   make([]VariableInfo, 0, len(manifest.Variables)),
+		Features:    make([]FeatureInfo, 0, len(manifest.Features)),
+		Hooks:       make([]HookInfo, 0, len(manifest.Hooks)),
+		Tools:       []ToolInfo{},
+		Files:       []TemplateEntry{},
+	}
+
+	for _, v := range manifest.Variables {
+		info.Variables = append(info.Variables, VariableInfo{
+			Name:     v.Name,
+			Type:     v.Type,
+			Default:  v.Default,
+			Required: v.Default == nil,
+			Pattern:  v.Pattern,
+			Choices:  v.Choices,
+			Help:     v.Help,
+		})
+	}
+
+	for _, f := range manifest.Features {
+		info.Features = append(info.Features, FeatureInfo{Name: f.Name, Default: f.Default, Paths: f.Paths, Help: f.Help})
+	}
+
+	tools := map[string]bool{}
+	for _, h := range manifest.Hooks {
+		info.Hooks = append(info.Hooks, HookInfo{Name: h.Name, Command: h.Run, Timeout: h.timeout.String(), Feature: h.Feature})
+
+		if tools[h.Run[0]] {
+			continue
+		}
+		tools[h.Run[0]] = true
+
+		_, err := exec.LookPath(h.Run[0])
+		info.Tools = append(info.Tools, ToolInfo{Name: h.Run[0], Install: h.Install, Installed: err == nil})
+	}
+
+	err = walkTemplateFiles(tmpl.FS, func(relPath string, d fs.DirEntry) error {
+		if relPath == ManifestFile || relPath == IgnoreFile {
+			return nil
+		}
+
+		fileInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+		info.Files = append(info.Files, TemplateEntry{Path: relPath, Size: fileInfo.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template '%s': %w", tmpl.Name, err)
+	}
+
+	return info, nil
+}
+
+// printTemplateInfo prints the details of a template
+func printTemplateInfo(w io.Writer, info *TemplateInfo) {
+	_, _ = fmt.Fprintf(w, "%s%s%s %s (%s)\n", ColorGreen, info.Name, ColorReset, orDash(info.Version), info.Source)
+	if info.Description != "" {
+		_, _ = fmt.Fprintf(w, "\n%s\n", info.Description)
+	}
+
+	if len(info.Variables) > 0 {
+		_, _ = fmt.Fprintln(w, "\nVariables (set with --set name=value):")
+		for _, v := range info.Variables {
+			details := v.Type
+			if v.Required {
+				details += ", required"
+			} else {
+				details += ", default " + *v.Default
+			}
+			if len(v.Choices) > 0 {
+				details += ", one of " + strings.Join(v.Choices, ", ")
+			}
+			if v.Pattern != "" {
+				details += ", matching " + v.Pattern
+			}
+			_, _ = fmt.Fprintf(w, "  %-18s %s (%s)\n", v.Name, v.Help, details)
+		}
+	}
+
+	if len(info.Features) > 0 {
+		_, _ = fmt.Fprintln(w, "\nFeatures (toggle with --with and --without):")
+		for _, f := range info.Features {
+			state := "off"
+			if f.Default {
+				state = "on"
+			}
+			_, _ = fmt.Fprintf(w, "  %-18s %s (%s by default)\n", f.Name, f.Help, state)
+		}
+	}
+
+	if len(info.Hooks) > 0 {
+		_, _ = fmt.Fprintln(w, "\nPost-generation hooks:")
+		for _, h := range info.Hooks {
+			line := strings.Join(h.Command, " ")
+			if h.Feature != "" {
+				line += " (with " + h.Feature + ")"
+			}
+			_, _ = fmt.Fprintf(w, "  → %s\n", line)
+		}
+	}
+
+	if len(info.Tools) > 0 {
+		_, _ = fmt.Fprintln(w, "\nRequired tools:")
+		for _, t := range info.Tools {
+			if t.Installed {
+				_, _ = fmt.Fprintf(w, "  %s✓ %s%s\n", ColorGreen, t.Name, ColorReset)
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "  %s✗ %s%s not found", ColorYellow, t.Name, ColorReset)
+			if t.Install != "" {
+				_, _ = fmt.Fprintf(w, " - install: %s", t.Install)
+			}
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+
+	tree := newTree()
+	total := 0
+	for _, f := range info.Files {
+		tree.add(f.Path, int(f.Size))
+		total += int(f.Size)
+	}
+	_, _ = fmt.Fprintf(w, "\nFiles (%d, %s):\n", len(info.Files), formatSize(total))
+	printTree(w, tree, "")
+}
+
+// printJSON prints v as indented JSON
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// orDash returns s, or a dash when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
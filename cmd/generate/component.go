@@ -0,0 +1,209 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/ironlabsdev/iron/internal/diff"
+	"golang.org/x/mod/modfile"
+)
+
+// componentsFS holds the skeletons of the components added to existing projects
+//
+//go:embed all:components
+var componentsFS embed.FS
+
+// existingProject is a project generated earlier that components are added to
+type existingProject struct {
+	// Root is the directory holding go.mod
+	Root       string
+	ModulePath string
+}
+
+// findProject finds the project containing dir by looking for go.mod in dir and its parents
+func findProject(dir string) (*existingProject, error) {
+	start, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	for root := start; ; root = filepath.Dir(root) {
+		content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			modulePath := modfile.ModulePath(content)
+			if modulePath == "" {
+				return nil, fmt.Errorf("no module path found in %s", filepath.Join(root, "go.mod"))
+			}
+			return &existingProject{Root: root, ModulePath: modulePath}, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read go.mod: %w", err)
+		}
+
+		if filepath.Dir(root) == root {
+			return nil, fmt.Errorf("'%s' is not inside a Go project (no go.mod found) - run this command in a project generated by iron", start)
+		}
+	}
+}
+
+// path returns the absolute path of a slash-separated project path
+func (p *existingProject) path(relPath string) string {
+	return filepath.Join(p.Root, filepath.FromSlash(relPath))
+}
+
+// exists reports whether a slash-separated project path exists
+func (p *existingProject) exists(relPath string) bool {
+	_, err := os.Stat(p.path(relPath))
+	return err == nil
+}
+
+// require fails unless every slash-separated project path exists, naming the first missing one
+func (p *existingProject) require(relPaths ...string) error {
+	for _, relPath := range relPaths {
+		if !p.exists(relPath) {
+			return fmt.Errorf("'%s' does not look like a project generated from the oauth template (missing %s)", p.Root, relPath)
+		}
+	}
+	return nil
+}
+
+// renderComponent renders a component skeleton such as handler/handler.go.tmpl.
+// Go files are formatted.
+func renderComponent(name string, data any) ([]byte, error) {
+	content, err := componentsFS.ReadFile(path.Join("components", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read component skeleton %s: %w", name, err)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse component skeleton %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render component skeleton %s: %w", name, err)
+	}
+	rendered := buf.Bytes()
+
+	if strings.HasSuffix(name, ".go.tmpl") {
+		formatted, err := format.Source(rendered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", name, err)
+		}
+		rendered = formatted
+	}
+
+	return rendered, nil
+}
+
+// componentChange is a file created or rewritten when adding a component
+type componentChange struct {
+	File
+	// previous holds the content being replaced, nil for new files
+	previous []byte
+}
+
+// newComponentChange prepares writing content to a slash-separated project
+// path, refusing to overwrite an existing file unless overwrite is set
+func (p *existingProject) newComponentChange(relPath string, content []byte, overwrite bool) (componentChange, error) {
+	change := componentChange{File: File{Path: relPath, Content: content, Mode: modeRegular}}
+
+	previous, err := os.ReadFile(p.path(relPath))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return change, nil
+	case err != nil:
+		return change, fmt.Errorf("failed to read %s: %w", relPath, err)
+	case !overwrite:
+		return change, fmt.Errorf("%s already exists - use --force to overwrite it", relPath)
+	}
+
+	change.previous = previous
+	return change, nil
+}
+
+// applyComponentChanges writes the changes into the project as one transaction,
+// so a failure leaves the project untouched
+func (p *existingProject) applyComponentChanges(changes []componentChange) error {
+	tx, err := newTransaction(p.Root)
+	if err != nil {
+		return err
+	}
+	defer func(tx *transaction) {
+		_ = tx.cleanup()
+	}(tx)
+
+	for _, change := range changes {
+		if err := tx.stage(change.File); err != nil {
+			return err
+		}
+	}
+
+	return tx.commit(context.Background())
+}
+
+// printComponentChanges summarises the changes, followed by their diffs when preview is set
+func printComponentChanges(w io.Writer, changes []componentChange, preview bool) {
+	var created, modified []string
+	for _, change := range changes {
+		if change.previous == nil {
+			created = append(created, change.Path)
+		} else {
+			modified = append(modified, change.Path)
+		}
+	}
+
+	printSections(w, []section{
+		{"Created", "+", ColorGreen, created},
+		{"Modified", "~", ColorYellow, modified},
+	})
+
+	if !preview {
+		return
+	}
+
+	for _, change := range changes {
+		from := "/dev/null"
+		if change.previous != nil {
+			from = "a/" + change.Path
+		}
+		edits := diff.Lines(diff.SplitLines(string(change.previous)), diff.SplitLines(string(change.Content)))
+		_, _ = fmt.Fprintf(w, "\n%s", diff.Unified(from, "b/"+change.Path, edits, 3))
+	}
+}
+
+// Install commands of the code generators run on projects
+const (
+	templInstall = "go install github.com/a-h/templ/cmd/templ@latest"
+	sqlcInstall  = "go install github.com/sqlc-dev/sqlc/cmd/sqlc@latest"
+)
+
+// runTool runs a code generator in the project when it is installed, and
+// otherwise explains how to install and run it
+func (p *existingProject) runTool(install string, command ...string) {
+	if _, err := exec.LookPath(command[0]); err != nil {
+		fmt.Printf("%s! %s not found in PATH - install it with %s, then run: %s%s\n",
+			ColorYellow, command[0], install, strings.Join(command, " "), ColorReset)
+		return
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = p.Root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("%s! %s failed: %v%s\n", ColorYellow, strings.Join(command, " "), err, ColorReset)
+	}
+}
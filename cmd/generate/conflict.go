@@ -0,0 +1,230 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/diff"
+)
+
+// ConflictStrategy decides what happens to files that already exist in the target directory
+type ConflictStrategy string
+
+const (
+	// ConflictAbort refuses to touch any existing file
+	ConflictAbort ConflictStrategy = ""
+	// ConflictForce overwrites existing files, keeping a .orig backup
+	ConflictForce ConflictStrategy = "force"
+	// ConflictSkip leaves existing files untouched
+	ConflictSkip ConflictStrategy = "skip-existing"
+	// ConflictMerge writes conflict markers around the lines that differ
+	ConflictMerge ConflictStrategy = "merge"
+)
+
+// backupSuffix is appended to files replaced with --force, followed by .1,
+// .2, ... when an earlier backup exists
+const backupSuffix = ".orig"
+
+// allowedExistingEntries can be present in the target directory without a conflict strategy,
+// so freshly cloned repositories can be scaffolded into
+var allowedExistingEntries = map[string]bool{
+	".git":           true,
+	".gitattributes": true,
+	".gitignore":     true,
+	"LICENSE":        true,
+	"README.md":      true,
+}
+
+// fileAction is what will happen to a single rendered file
+type fileAction int
+
+const (
+	actionCreate fileAction = iota
+	actionUnchanged
+	actionConflict
+)
+
+// plannedFile pairs a rendered file with the action it requires
+type plannedFile struct {
+	File
+	action fileAction
+	// existing holds the current content of conflicting text files, and is nil
+	// when either side is binary
+	existing []byte
+}
+
+// Result summarises what generation did to the target directory
+type Result struct {
+	Created     []string
+	Overwritten []string
+	Skipped     []string
+	Conflicts   []string
+}
+
+// planFiles compares the rendered files against the target directory
+func planFiles(fullPath string, files []File) ([]plannedFile, error) {
+	plan := make([]plannedFile, 0, len(files))
+
+	for _, file := range files {
+		destPath := filepath.Join(fullPath, filepath.FromSlash(file.Path))
+
+		same, err := sameContent(destPath, file)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			plan = append(plan, plannedFile{File: file, action: actionCreate})
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to read existing file %s: %w", file.Path, err)
+		case same, file.CreateOnly:
+			// Create-only files belong to the user once they exist
+			plan = append(plan, plannedFile{File: file, action: actionUnchanged})
+			continue
+		}
+
+		planned := plannedFile{File: file, action: actionConflict}
+
+		// Only text files can be merged, so only they need their existing content
+		if !file.Binary && file.stream == nil {
+			existing, err := os.ReadFile(destPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read existing file %s: %w", file.Path, err)
+			}
+			if !isBinary(existing) {
+				planned.existing = existing
+			}
+		}
+
+		plan = append(plan, planned)
+	}
+
+	return plan, nil
+}
+
+// conflictingPaths lists the planned files that differ from existing files
+func conflictingPaths(plan []plannedFile) []string {
+	var paths []string
+	for _, p := range plan {
+		if p.action == actionConflict {
+			paths = append(paths, p.Path)
+		}
+	}
+	return paths
+}
+
+// applyPlan stages the planned files in tx using the conflict strategy
+func applyPlan(ctx context.Context, tx *transaction, plan []plannedFile, strategy ConflictStrategy) (*Result, error) {
+	if conflicts := conflictingPaths(plan); len(conflicts) > 0 && strategy == ConflictAbort {
+		return nil, fmt.Errorf("%d existing files would be overwritten:\n  %s\nUse --force, --skip-existing or --merge to resolve them",
+			len(conflicts), strings.Join(conflicts, "\n  "))
+	}
+
+	result := &Result{}
+
+	for _, p := range plan {
+		if ctx.Err() != nil {
+			return result, errInterrupted
+		}
+
+		switch p.action {
+		case actionUnchanged:
+			result.Skipped = append(result.Skipped, p.Path)
+			continue
+		case actionCreate:
+			if err := tx.stage(p.File); err != nil {
+				return result, err
+			}
+			result.Created = append(result.Created, p.Path)
+			continue
+		}
+
+		switch {
+		case strategy == ConflictSkip:
+			result.Skipped = append(result.Skipped, p.Path)
+		case strategy == ConflictForce, p.existing == nil:
+			// Binary files cannot be merged, so --merge keeps them like --force does
+			if err := tx.stage(p.File); err != nil {
+				return result, err
+			}
+			tx.backup(p.Path)
+			result.Overwritten = append(result.Overwritten, p.Path)
+		case strategy == ConflictMerge:
+			merged := p.File
+			merged.Content = conflictMarkers(p.existing, p.Content)
+			if err := tx.stage(merged); err != nil {
+				return result, err
+			}
+			result.Conflicts = append(result.Conflicts, p.Path)
+		}
+	}
+
+	return result, nil
+}
+
+// conflictMarkers merges the existing and the generated content, with
+// git-style markers around the lines where they differ
+func conflictMarkers(existing, generated []byte) []byte {
+	merged, _ := diff.Merge3("", string(existing), string(generated), diff.MergeLabels{Ours: "existing", Theirs: "template"})
+	return []byte(merged)
+}
+
+// printPlan lists the existing files the generation would touch
+func printPlan(w io.Writer, plan []plannedFile, strategy ConflictStrategy) {
+	conflicts := conflictingPaths(plan)
+	if len(conflicts) == 0 {
+		return
+	}
+
+	action := "would be overwritten"
+	switch strategy {
+	case ConflictForce:
+		action = "would be overwritten (backed up as " + backupSuffix + ")"
+	case ConflictSkip:
+		action = "would be skipped"
+	case ConflictMerge:
+		action = "would get conflict markers around the lines that differ"
+	}
+
+	_, _ = fmt.Fprintf(w, "\nExisting files that %s:\n", action)
+	for _, path := range conflicts {
+		_, _ = fmt.Fprintf(w, "  ! %s\n", path)
+	}
+}
+
+// section is a titled list of paths in a summary
+type section struct {
+	title string
+	mark  string
+	color string
+	paths []string
+}
+
+// printResult prints the summary of a generation
+func printResult(w io.Writer, result *Result) {
+	printSections(w, []section{
+		{"Created", "+", ColorGreen, result.Created},
+		{"Overwritten (backed up as " + backupSuffix + ")", "~", ColorYellow, result.Overwritten},
+		{"Skipped", "-", ColorBlue, result.Skipped},
+		{"Conflicts on the lines that differ (resolve the markers)", "!", ColorYellow, result.Conflicts},
+	})
+}
+
+// printSections prints the non-empty sections with their sorted paths
+func printSections(w io.Writer, sections []section) {
+	for _, s := range sections {
+		if len(s.paths) == 0 {
+			continue
+		}
+
+		sort.Strings(s.paths)
+		_, _ = fmt.Fprintf(w, "%s%s (%d):%s\n", s.color, s.title, len(s.paths), ColorReset)
+		for _, path := range s.paths {
+			_, _ = fmt.Fprintf(w, "  %s %s\n", s.mark, path)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package generate
+
+import "testing"
+
+func TestConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name                      string
+		existing, generated, want string
+	}{
+		{
+			name:      "one line differs",
+			existing:  "package main\n\nconst port = 80\n\nfunc main() {}\n",
+			generated: "package main\n\nconst port = 8080\n\nfunc main() {}\n",
+			want:      "package main\n\n<<<<<<< existing\nconst port = 80\n=======\nconst port = 8080\n>>>>>>> template\n\nfunc main() {}\n",
+		},
+		{
+			name:      "added lines",
+			existing:  "a\nc\n",
+			generated: "a\nb\nc\n",
+			want:      "a\n<<<<<<< existing\n=======\nb\n>>>>>>> template\nc\n",
+		},
+		{
+			name:      "no trailing newline",
+			existing:  "a\nold",
+			generated: "a\nnew",
+			want:      "a\n<<<<<<< existing\nold\n=======\nnew\n>>>>>>> template\n",
+		},
+		{
+			name:      "nothing in common",
+			existing:  "old\n",
+			generated: "new\n",
+			want:      "<<<<<<< existing\nold\n=======\nnew\n>>>>>>> template\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(conflictMarkers([]byte(tt.existing), []byte(tt.generated))); got != tt.want {
+				t.Errorf("conflictMarkers(%q, %q) =\n%s\nwant:\n%s", tt.existing, tt.generated, got, tt.want)
+			}
+		})
+	}
+}
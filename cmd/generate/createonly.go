@@ -0,0 +1,72 @@
+package generate
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+)
+
+// CreateOnlyFile is generated only when it does not exist yet, and belongs to
+// the user afterwards: it is never overwritten, merged, upgraded or reported as
+// drift. Path is a glob of template files, or with From the path of a file
+// created from another template file, e.g. .env from .env.example.
+type CreateOnlyFile struct {
+	Path string `yaml:"path"`
+	From string `yaml:"from"`
+}
+
+// validateCreateOnly checks the create_only entries of the manifest
+func (m *Manifest) validateCreateOnly() error {
+	for _, c := range m.CreateOnly {
+		if c.Path == "" {
+			return fmt.Errorf("create_only entries need a path")
+		}
+		if c.From == "" {
+			continue
+		}
+
+		if !fs.ValidPath(c.From) || c.From == "." {
+			return fmt.Errorf("create_only source '%s' must be a path inside the template", c.From)
+		}
+		if strings.ContainsAny(c.Path, "*?[") {
+			return fmt.Errorf("create_only path '%s' cannot be a glob when created from '%s'", c.Path, c.From)
+		}
+	}
+	return nil
+}
+
+// createOnly reports whether the template file at relPath is generated only when absent
+func (m *Manifest) createOnly(relPath string) bool {
+	for _, c := range m.CreateOnly {
+		if c.From == "" && utils.MatchGlob(c.Path, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// createdFrom returns the create_only entries copying the template file at relPath
+func (m *Manifest) createdFrom(relPath string) []CreateOnlyFile {
+	var copies []CreateOnlyFile
+	for _, c := range m.CreateOnly {
+		if c.From == relPath {
+			copies = append(copies, c)
+		}
+	}
+	return copies
+}
+
+// checkCreateOnlySources makes sure every create_only source exists in the template
+func (m *Manifest) checkCreateOnlySources(fsys fs.FS) error {
+	for _, c := range m.CreateOnly {
+		if c.From == "" {
+			continue
+		}
+		if _, err := fs.Stat(fsys, c.From); err != nil {
+			return fmt.Errorf("create_only source '%s' of '%s' is not in the template", c.From, c.Path)
+		}
+	}
+	return nil
+}
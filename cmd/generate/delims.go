@@ -0,0 +1,151 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+)
+
+// rawSuffix marks template files copied verbatim. The suffix is removed from the generated file.
+const rawSuffix = ".raw"
+
+// Default text/template delimiters
+const (
+	defaultLeftDelim  = "{{"
+	defaultRightDelim = "}}"
+)
+
+// directiveRe matches an iron directive comment such as "# iron:raw" or
+// "// iron:delims [[ ]]" on the first line of a template file
+var directiveRe = regexp.MustCompile(`^\s*(?://|#|--|<!--|/\*)\s*iron:(raw|delims\s+(\S+)\s+(\S+))\s*(?:-->|\*/)?\s*$`)
+
+// FileRule changes how the template files matching its paths are rendered
+type FileRule struct {
+	// Paths are globs of template files and directories the rule applies to
+	Paths []string `yaml:"paths"`
+	// Delimiters replace {{ and }}, e.g. ["[[", "]]"]
+	Delimiters []string `yaml:"delimiters"`
+	// Raw files are copied without being rendered
+	Raw bool `yaml:"raw"`
+}
+
+// fileTemplate is how a single template file is rendered
+type fileTemplate struct {
+	raw   bool
+	left  string
+	right string
+}
+
+// validateDelimiters checks the template-wide delimiters and the file rules of the manifest
+func (m *Manifest) validateDelimiters() error {
+	if err := checkDelimiters(m.Delimiters); err != nil {
+		return err
+	}
+
+	for i, rule := range m.Files {
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("file rule %d has no paths", i+1)
+		}
+		for _, pattern := range rule.Paths {
+			if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+				return fmt.Errorf("file rule %d has an invalid path pattern '%s': %w", i+1, pattern, err)
+			}
+		}
+
+		if rule.Raw && len(rule.Delimiters) > 0 {
+			return fmt.Errorf("file rule %d sets both raw and delimiters", i+1)
+		}
+		if err := checkDelimiters(rule.Delimiters); err != nil {
+			return fmt.Errorf("file rule %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// checkDelimiters accepts no delimiters or a pair of distinct, non-empty ones
+func checkDelimiters(delims []string) error {
+	if len(delims) == 0 {
+		return nil
+	}
+	if len(delims) != 2 || delims[0] == "" || delims[1] == "" || delims[0] == delims[1] {
+		return fmt.Errorf("delimiters must be two different non-empty strings, e.g. [\"[[\", \"]]\"], got %q", delims)
+	}
+	return nil
+}
+
+// fileTemplate decides how relPath is rendered and strips its directive line,
+// if any. A directive on the first line of the file wins over the first
+// matching file rule, which wins over the template-wide delimiters.
+func (m *Manifest) fileTemplate(relPath string, content []byte) (fileTemplate, []byte, error) {
+	ft := fileTemplate{left: defaultLeftDelim, right: defaultRightDelim}
+	if len(m.Delimiters) == 2 {
+		ft.left, ft.right = m.Delimiters[0], m.Delimiters[1]
+	}
+
+	if rule, ok := m.fileRule(relPath); ok {
+		ft.raw = rule.Raw
+		if len(rule.Delimiters) == 2 {
+			ft.left, ft.right = rule.Delimiters[0], rule.Delimiters[1]
+		}
+	}
+
+	start, end, match := findDirective(content)
+	if match == nil {
+		return ft, content, nil
+	}
+
+	if match[1] == "raw" {
+		ft.raw = true
+	} else {
+		if err := checkDelimiters(match[2:4]); err != nil {
+			return ft, nil, fmt.Errorf("invalid iron:delims directive in %s: %w", relPath, err)
+		}
+		ft.raw, ft.left, ft.right = false, match[2], match[3]
+	}
+
+	stripped := append(content[:start:start], content[end:]...)
+	return ft, stripped, nil
+}
+
+// fileRule returns the first file rule matching relPath or one of its parent directories
+func (m *Manifest) fileRule(relPath string) (FileRule, bool) {
+	for _, rule := range m.Files {
+		for _, pattern := range rule.Paths {
+			pattern = strings.TrimSuffix(pattern, "/")
+			for p := relPath; p != "."; p = path.Dir(p) {
+				if utils.MatchGlob(pattern, p) {
+					return rule, true
+				}
+			}
+		}
+	}
+	return FileRule{}, false
+}
+
+// findDirective looks for a directive on the first line of content, or on the
+// line after a leading //go:build constraint. It returns the byte range of the
+// directive line, including its line break, and the directive submatches.
+func findDirective(content []byte) (int, int, []string) {
+	start := 0
+	for i := 0; i < 2 && start < len(content); i++ {
+		end := len(content)
+		if nl := bytes.IndexByte(content[start:], '\n'); nl >= 0 {
+			end = start + nl + 1
+		}
+
+		line := strings.TrimRight(string(content[start:end]), "\r\n")
+		if m := directiveRe.FindStringSubmatch(line); m != nil {
+			return start, end, m
+		}
+		if !strings.HasPrefix(line, "//go:build") {
+			break
+		}
+		start = end
+	}
+	return 0, 0, nil
+}
@@ -0,0 +1,359 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of commands that print reports
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+var (
+	diffStat     bool
+	diffNameOnly bool
+	diffOutput   string
+)
+
+// DiffCmd shows how a project has drifted from its template
+var DiffCmd = &cobra.Command{
+	Use:   "diff [project-dir]",
+	Short: "Show how a generated project differs from its template",
+	Long: `Show how a generated project has changed since it was generated.
+
+The template recorded in .iron/ is rendered again in memory with the project's
+recorded variables and compared against the files on disk. Every file is
+classified as unchanged, modified or deleted, and files that are not part of
+the template are reported as added. Files created or rewritten by the
+template's post-generation hooks, such as go.sum, count as unchanged until
+they are edited.
+
+The project directory defaults to the current directory.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+
+		if diffStat && diffNameOnly {
+			return fmt.Errorf("--stat and --name-only cannot be used together")
+		}
+		if diffOutput != OutputText && diffOutput != OutputJSON {
+			return fmt.Errorf("invalid output format '%s' (valid formats: %s, %s)", diffOutput, OutputText, OutputJSON)
+		}
+
+		fullPath, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve project directory: %w", err)
+		}
+
+		drift, err := Diff(fullPath, templateDirs())
+		if err != nil {
+			return err
+		}
+
+		if diffOutput == OutputJSON {
+			return printDriftJSON(os.Stdout, drift)
+		}
+
+		switch {
+		case diffStat:
+			printDriftStat(os.Stdout, drift, isTerminal(os.Stdout))
+		case diffNameOnly:
+			for _, file := range drift.Changed() {
+				fmt.Println(file.Path)
+			}
+		default:
+			printDriftDiff(os.Stdout, drift, isTerminal(os.Stdout))
+		}
+		return nil
+	},
+}
+
+func init() {
+	DiffCmd.Flags().BoolVar(&diffStat, "stat", false, "Show a per-file summary of the changes instead of the diff")
+	DiffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "Show only the paths of changed files")
+	DiffCmd.Flags().StringVarP(&diffOutput, "output", "o", OutputText, "Output format: text or json")
+	DiffCmd.Flags().StringVar(&templateDir, "template-dir", "", "Directory to load templates from before the configured and built-in ones")
+}
+
+// DriftStatus classifies a file of a project against its template
+type DriftStatus string
+
+const (
+	DriftUnchanged DriftStatus = "unchanged"
+	DriftModified  DriftStatus = "modified"
+	DriftDeleted   DriftStatus = "deleted"
+	DriftAdded     DriftStatus = "added"
+)
+
+// FileDrift describes the changes made to a single file
+type FileDrift struct {
+	Path       string      `json:"path"`
+	Status     DriftStatus `json:"status"`
+	Insertions int         `json:"insertions"`
+	Deletions  int         `json:"deletions"`
+	Binary     bool        `json:"binary,omitempty"`
+	// Diff is the unified diff from the rendered template to the file on disk
+	Diff string `json:"diff,omitempty"`
+}
+
+// Drift describes how a project differs from its template
+type Drift struct {
+	Template        string      `json:"template"`
+	TemplateVersion string      `json:"templateVersion,omitempty"`
+	Project         string      `json:"project"`
+	Files           []FileDrift `json:"files"`
+}
+
+// Changed lists the files that are not unchanged
+func (d *Drift) Changed() []FileDrift {
+	var changed []FileDrift
+	for _, file := range d.Files {
+		if file.Status != DriftUnchanged {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+// Diff renders the template of the project at fullPath and compares it to the files on disk
+func Diff(fullPath string, dirs []string) (*Drift, error) {
+	lock, err := ReadLockfile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := ReadSnapshot(fullPath, lock.Template)
+	if err != nil {
+		// Projects generated before snapshots existed are compared to the current template
+		sources, srcErr := Sources(dirs)
+		if srcErr != nil {
+			return nil, srcErr
+		}
+		if tmpl, srcErr = ResolveTemplate(lock.Template, sources); srcErr != nil {
+			return nil, err
+		}
+	}
+
+	project, err := renderLocked(tmpl, lock, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	if project.TemplateChecksum != lock.TemplateChecksum {
+		_, _ = fmt.Fprintf(os.Stderr, "%s! the %s template changed since the project was generated - comparing against the current version%s\n",
+			ColorYellow, lock.Template, ColorReset)
+	}
+
+	drift := &Drift{Template: lock.Template, TemplateVersion: lock.TemplateVersion, Project: fullPath}
+	rendered, err := filesByPath(project.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range project.Files {
+		content := rendered[file.Path]
+		ours, err := os.ReadFile(filepath.Join(fullPath, filepath.FromSlash(file.Path)))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			drift.Files = append(drift.Files, compareFile(file.Path, DriftDeleted, content, nil))
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		case file.CreateOnly, bytes.Equal(ours, content), lock.hookOwned(file.Path, ours):
+			drift.Files = append(drift.Files, FileDrift{Path: file.Path, Status: DriftUnchanged})
+		default:
+			drift.Files = append(drift.Files, compareFile(file.Path, DriftModified, content, ours))
+		}
+	}
+
+	err = filepath.WalkDir(fullPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" || filePath == filepath.Join(fullPath, ".iron") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(fullPath, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if _, ok := rendered[relPath]; ok {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if lock.hookOwned(relPath, content) {
+			drift.Files = append(drift.Files, FileDrift{Path: relPath, Status: DriftUnchanged})
+			return nil
+		}
+		drift.Files = append(drift.Files, compareFile(relPath, DriftAdded, nil, content))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project files: %w", err)
+	}
+
+	sort.Slice(drift.Files, func(i, j int) bool {
+		return drift.Files[i].Path < drift.Files[j].Path
+	})
+
+	return drift, nil
+}
+
+// compareFile diffs the rendered content of a file against its content on disk
+func compareFile(path string, status DriftStatus, rendered, ours []byte) FileDrift {
+	file := FileDrift{Path: path, Status: status}
+	if isBinary(rendered) || isBinary(ours) {
+		file.Binary = true
+		return file
+	}
+
+	from, to := "a/"+path, "b/"+path
+	switch status {
+	case DriftAdded:
+		from = "/dev/null"
+	case DriftDeleted:
+		to = "/dev/null"
+	}
+
+	edits := diff.Lines(diff.SplitLines(string(rendered)), diff.SplitLines(string(ours)))
+	file.Insertions, file.Deletions = diff.Count(edits)
+	file.Diff = diff.Unified(from, to, edits, 3)
+	return file
+}
+
+// printDriftDiff prints the unified diff of every changed file, colored when color is set
+func printDriftDiff(w io.Writer, drift *Drift, color bool) {
+	for _, file := range drift.Changed() {
+		_, _ = fmt.Fprintf(w, "iron diff %s (%s)\n", file.Path, file.Status)
+		if file.Binary {
+			_, _ = fmt.Fprintf(w, "Binary files differ\n")
+			continue
+		}
+
+		for _, line := range diff.SplitLines(file.Diff) {
+			lineColor := ""
+			if color {
+				switch {
+				case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				case strings.HasPrefix(line, "@@"):
+					lineColor = ColorBlue
+				case strings.HasPrefix(line, "+"):
+					lineColor = ColorGreen
+				case strings.HasPrefix(line, "-"):
+					lineColor = ColorRed
+				}
+			}
+
+			if lineColor == "" {
+				_, _ = io.WriteString(w, line)
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "%s%s%s\n", lineColor, strings.TrimSuffix(line, "\n"), ColorReset)
+		}
+	}
+
+	printDriftSummary(w, drift)
+}
+
+// printDriftStat prints a line per changed file with a bar of its insertions and deletions
+func printDriftStat(w io.Writer, drift *Drift, color bool) {
+	changed := drift.Changed()
+
+	width, most := 0, 0
+	for _, file := range changed {
+		width = max(width, len(file.Path))
+		most = max(most, file.Insertions+file.Deletions)
+	}
+
+	const maxBar = 40
+	for _, file := range changed {
+		if file.Binary {
+			_, _ = fmt.Fprintf(w, " %s %-*s | Bin\n", statusMark(file.Status), width, file.Path)
+			continue
+		}
+
+		insertions, deletions := file.Insertions, file.Deletions
+		if most > maxBar {
+			insertions = (insertions*maxBar + most - 1) / most
+			deletions = (deletions*maxBar + most - 1) / most
+		}
+		plus, minus := strings.Repeat("+", insertions), strings.Repeat("-", deletions)
+		if color {
+			plus, minus = ColorGreen+plus+ColorReset, ColorRed+minus+ColorReset
+		}
+		_, _ = fmt.Fprintf(w, " %s %-*s | %5d %s%s\n", statusMark(file.Status), width, file.Path,
+			file.Insertions+file.Deletions, plus, minus)
+	}
+
+	printDriftSummary(w, drift)
+}
+
+// printDriftSummary prints the number of files in each state
+func printDriftSummary(w io.Writer, drift *Drift) {
+	counts := map[DriftStatus]int{}
+	insertions, deletions := 0, 0
+	for _, file := range drift.Files {
+		counts[file.Status]++
+		insertions += file.Insertions
+		deletions += file.Deletions
+	}
+
+	if len(drift.Changed()) == 0 {
+		_, _ = fmt.Fprintf(w, "%s✓ Project matches the %s template (%d files)%s\n", ColorGreen, drift.Template, counts[DriftUnchanged], ColorReset)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%s%d modified, %d deleted, %d added, %d unchanged (%d insertions(+), %d deletions(-))%s\n",
+		ColorBlue, counts[DriftModified], counts[DriftDeleted], counts[DriftAdded], counts[DriftUnchanged],
+		insertions, deletions, ColorReset)
+}
+
+// printDriftJSON prints the drift as indented JSON
+func printDriftJSON(w io.Writer, drift *Drift) error {
+	if diffStat || diffNameOnly {
+		for i := range drift.Files {
+			drift.Files[i].Diff = ""
+		}
+	}
+
+	return printJSON(w, drift)
+}
+
+// statusMark is the single letter shown for a status
+func statusMark(status DriftStatus) string {
+	switch status {
+	case DriftModified:
+		return "M"
+	case DriftDeleted:
+		return "D"
+	case DriftAdded:
+		return "A"
+	}
+	return " "
+}
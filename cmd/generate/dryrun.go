@@ -0,0 +1,200 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/txtar"
+)
+
+// Dry run output modes
+const (
+	DryRunTree    = "tree"
+	DryRunContent = "content"
+	DryRunTxtar   = "txtar"
+)
+
+// treeNode is a directory entry in the dry run file tree
+type treeNode struct {
+	name     string
+	size     int
+	children map[string]*treeNode
+}
+
+// validateDryRunMode checks that mode is a supported dry run output mode
+func validateDryRunMode(mode string) error {
+	switch mode {
+	case "", DryRunTree, DryRunContent, DryRunTxtar:
+		return nil
+	}
+	return fmt.Errorf("invalid dry run mode '%s' - use %s, %s or %s", mode, DryRunTree, DryRunContent, DryRunTxtar)
+}
+
+// printDryRun writes a preview of the rendered project to w
+func printDryRun(w io.Writer, project *Project, fullPath, mode string) error {
+	if mode == DryRunTxtar {
+		_, err := w.Write(txtar.Format(projectArchive(project)))
+		return err
+	}
+
+	total := 0
+	for _, file := range project.Files {
+		total += int(file.Size())
+	}
+
+	_, _ = fmt.Fprintf(w, "%sDry run: %s project in '%s' (no files written)%s\n\n", ColorBlue, project.Template, fullPath, ColorReset)
+
+	_, _ = fmt.Fprintln(w, "Variables:")
+	for _, v := range project.Data.variables() {
+		_, _ = fmt.Fprintf(w, "  %-24s %s\n", v[0], v[1])
+	}
+
+	_, _ = fmt.Fprintf(w, "\nFiles (%d, %s):\n", len(project.Files), formatSize(total))
+	printTree(w, buildTree(project.Files), "")
+
+	if mode == DryRunContent {
+		for _, file := range project.Files {
+			_, _ = fmt.Fprintf(w, "\n%s==> %s <==%s\n", ColorGreen, file.Path, ColorReset)
+			if file.Binary || file.stream != nil {
+				_, _ = fmt.Fprintf(w, "(%s copied verbatim, %s)\n", fileKind(file), formatSize(int(file.Size())))
+				continue
+			}
+			_, _ = w.Write(file.Content)
+			if len(file.Content) > 0 && file.Content[len(file.Content)-1] != '\n' {
+				_, _ = fmt.Fprintln(w)
+			}
+		}
+	}
+
+	return nil
+}
+
+// projectArchive bundles the rendered files into a txtar archive whose comment
+// records the template and its variables
+func projectArchive(project *Project) *txtar.Archive {
+	var comment strings.Builder
+	_, _ = fmt.Fprintf(&comment, "template: %s\n", project.Template)
+	for _, v := range project.Data.variables() {
+		_, _ = fmt.Fprintf(&comment, "%s: %s\n", v[0], v[1])
+	}
+
+	archive := &txtar.Archive{Comment: []byte(comment.String())}
+	for _, file := range project.Files {
+		data := file.Content
+		// txtar only holds text, so binary and large files are described instead
+		if file.Binary || file.stream != nil {
+			data = []byte(fmt.Sprintf("(%s copied verbatim, %d bytes)\n", fileKind(file), file.Size()))
+		}
+		archive.Files = append(archive.Files, txtar.File{Name: file.Path, Data: data})
+	}
+
+	return archive
+}
+
+// variables lists the template values as name/value pairs in declaration order,
+// with map values such as Vars expanded to Vars.<name>
+func (d templateData) variables() [][2]string {
+	v := reflect.ValueOf(d)
+	t := v.Type()
+
+	var vars [][2]string
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Map {
+			vars = append(vars, [2]string{t.Field(i).Name, fmt.Sprint(field.Interface())})
+			continue
+		}
+
+		keys := field.MapKeys()
+		sort.Slice(keys, func(a, b int) bool { return keys[a].String() < keys[b].String() })
+		for _, key := range keys {
+			vars = append(vars, [2]string{t.Field(i).Name + "." + key.String(), fmt.Sprint(field.MapIndex(key).Interface())})
+		}
+	}
+
+	return vars
+}
+
+// buildTree arranges the rendered files into a directory tree
+func buildTree(files []File) *treeNode {
+	root := newTree()
+	for _, file := range files {
+		root.add(file.Path, int(file.Size()))
+	}
+	return root
+}
+
+// newTree returns an empty directory tree
+func newTree() *treeNode {
+	return &treeNode{children: map[string]*treeNode{}}
+}
+
+// add places the file at the slash-separated relPath in the tree
+func (n *treeNode) add(relPath string, size int) {
+	node := n
+	parts := strings.Split(relPath, "/")
+	for i, part := range parts {
+		child, ok := node.children[part]
+		if !ok {
+			child = &treeNode{name: part}
+			if i < len(parts)-1 {
+				child.children = map[string]*treeNode{}
+			}
+			node.children[part] = child
+		}
+		node = child
+	}
+	node.size = size
+}
+
+// printTree writes the children of node using box-drawing connectors
+func printTree(w io.Writer, node *treeNode, prefix string) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		child := node.children[name]
+		connector, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			connector, indent = "└── ", "    "
+		}
+
+		if child.children != nil {
+			_, _ = fmt.Fprintf(w, "%s%s%s%s/%s\n", prefix, connector, ColorBlue, name, ColorReset)
+			printTree(w, child, prefix+indent)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s%s%s (%s)\n", prefix, connector, name, formatSize(child.size))
+	}
+}
+
+// formatSize formats a byte count for humans
+func formatSize(size int) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := unit, 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGT"[exp])
+}
+
+// fileKind describes a file that is not shown in previews
+func fileKind(file File) string {
+	if file.Binary {
+		return "binary file"
+	}
+	return "large file"
+}
@@ -0,0 +1,133 @@
+package generate
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+)
+
+// Feature is an optional part of a template that can be turned on or off
+// with --with and --without. Its state is available as .Features.<name>.
+type Feature struct {
+	Name    string `yaml:"name"`
+	Help    string `yaml:"help"`
+	Default bool   `yaml:"default"`
+	// Paths are globs of template files and directories only generated when
+	// the feature is enabled. A directory pattern excludes its whole tree.
+	Paths []string `yaml:"paths"`
+}
+
+// Feature returns the declared feature called name
+func (m *Manifest) Feature(name string) (Feature, bool) {
+	for _, f := range m.Features {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Feature{}, false
+}
+
+// validateFeatures checks the feature declarations of the manifest
+func (m *Manifest) validateFeatures() error {
+	seen := map[string]bool{}
+
+	for _, f := range m.Features {
+		if !variableNameRe.MatchString(f.Name) {
+			return fmt.Errorf("feature name '%s' must be a letter or underscore followed by letters, digits or underscores", f.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("feature '%s' is declared more than once", f.Name)
+		}
+		seen[f.Name] = true
+
+		for _, pattern := range f.Paths {
+			if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+				return fmt.Errorf("feature '%s' has an invalid path pattern '%s': %w", f.Name, pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResolveFeatures applies the user's choices on top of the feature defaults
+func (m *Manifest) ResolveFeatures(choices map[string]bool) (map[string]bool, error) {
+	var unknown []string
+	for name := range choices {
+		if _, ok := m.Feature(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown features: %s%s", strings.Join(unknown, ", "), m.featuresHelp())
+	}
+
+	features := make(map[string]bool, len(m.Features))
+	for _, f := range m.Features {
+		enabled, ok := choices[f.Name]
+		if !ok {
+			enabled = f.Default
+		}
+		features[f.Name] = enabled
+	}
+
+	return features, nil
+}
+
+// excluded reports whether a template path belongs to a disabled feature
+func (m *Manifest) excluded(relPath string, features map[string]bool) bool {
+	for _, f := range m.Features {
+		if features[f.Name] {
+			continue
+		}
+
+		for _, pattern := range f.Paths {
+			pattern = strings.TrimSuffix(pattern, "/")
+			if utils.MatchGlob(pattern, relPath) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// featuresHelp lists the declared features for error messages
+func (m *Manifest) featuresHelp() string {
+	if len(m.Features) == 0 {
+		return fmt.Sprintf("\nTemplate '%s' does not declare any features", m.Name)
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "\nFeatures of template '%s' (toggle with --with and --without):", m.Name)
+	for _, f := range m.Features {
+		state := "off"
+		if f.Default {
+			state = "on"
+		}
+		_, _ = fmt.Fprintf(&b, "\n  %-18s %s (default %s)", f.Name, f.Help, state)
+	}
+	return b.String()
+}
+
+// parseFeatureFlags merges --with and --without into a map of choices
+func parseFeatureFlags(with, without []string) (map[string]bool, error) {
+	choices := make(map[string]bool, len(with)+len(without))
+
+	for _, name := range with {
+		choices[name] = true
+	}
+	for _, name := range without {
+		if choices[name] {
+			return nil, fmt.Errorf("feature '%s' cannot be both enabled and disabled", name)
+		}
+		choices[name] = false
+	}
+
+	return choices, nil
+}
@@ -0,0 +1,257 @@
+package generate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+)
+
+const (
+	// largeFileSize is the size from which files copied verbatim are streamed
+	// from the template when written instead of being held in memory
+	largeFileSize = 1 << 20
+	// sniffSize is how much of a file is inspected to tell whether it is binary
+	sniffSize = 8000
+)
+
+// File permissions of generated files
+const (
+	modeRegular    fs.FileMode = 0644
+	modeExecutable fs.FileMode = 0755
+)
+
+// isBinary reports whether content looks like binary data
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), sniffSize)], 0) >= 0
+}
+
+// fileMode decides whether a template file is generated executable: when the
+// manifest lists it, when it is a shell script or starts with #!, or when the
+// template directory on disk has it executable. Embedded templates lose their
+// modes, so the latter only applies to custom template directories.
+func (m *Manifest) fileMode(relPath string, info fs.FileInfo, head []byte) fs.FileMode {
+	for _, pattern := range m.Executable {
+		if utils.MatchGlob(pattern, relPath) {
+			return modeExecutable
+		}
+	}
+
+	if path.Ext(relPath) == ".sh" || bytes.HasPrefix(head, []byte("#!")) || info.Mode().Perm()&0111 != 0 {
+		return modeExecutable
+	}
+
+	return modeRegular
+}
+
+// rendered reports whether a template file is changed on its way to the project,
+// by template processing or by module path rewriting
+func rendered(relPath string) bool {
+	name := path.Base(relPath)
+	return shouldProcessAsTemplate(relPath) || name == "go.mod.template" ||
+		strings.HasSuffix(name, ".go") || strings.HasSuffix(name, ".templ")
+}
+
+// streamSource is a large template file copied verbatim without being loaded
+type streamSource struct {
+	fsys fs.FS
+	path string
+	size int64
+}
+
+// Size is the size of the generated file in bytes
+func (f File) Size() int64 {
+	if f.stream != nil {
+		return f.stream.size
+	}
+	return int64(len(f.Content))
+}
+
+// open returns a reader of the generated content
+func (f File) open() (io.ReadCloser, error) {
+	if f.stream != nil {
+		return f.stream.fsys.Open(f.stream.path)
+	}
+	return io.NopCloser(bytes.NewReader(f.Content)), nil
+}
+
+// Bytes returns the generated content, reading streamed files into memory
+func (f File) Bytes() ([]byte, error) {
+	if f.stream == nil {
+		return f.Content, nil
+	}
+
+	content, err := fs.ReadFile(f.stream.fsys, f.stream.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file %s: %w", f.stream.path, err)
+	}
+	return content, nil
+}
+
+// checksum is the SHA-256 of the generated content
+func (f File) checksum() (string, error) {
+	if f.stream == nil {
+		return checksum(f.Content), nil
+	}
+
+	r, err := f.open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read template file %s: %w", f.stream.path, err)
+	}
+	defer func(r io.ReadCloser) {
+		_ = r.Close()
+	}(r)
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to read template file %s: %w", f.stream.path, err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeProjectFile writes a generated file with its mode, streaming large files from the template
+func writeProjectFile(destPath string, file File) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	mode := file.Mode
+	if mode == 0 {
+		mode = modeRegular
+	}
+
+	r, err := file.open()
+	if err != nil {
+		return fmt.Errorf("failed to read template file for %s: %w", file.Path, err)
+	}
+	defer func(r io.ReadCloser) {
+		_ = r.Close()
+	}(r)
+
+	f, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", destPath, err)
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", destPath, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", destPath, err)
+	}
+
+	// The mode passed to OpenFile only applies to new files
+	if err := os.Chmod(destPath, mode); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %w", destPath, err)
+	}
+
+	return nil
+}
+
+// sameContent reports whether the file at destPath holds exactly the generated content
+func sameContent(destPath string, file File) (bool, error) {
+	info, err := os.Stat(destPath)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != file.Size() {
+		return false, nil
+	}
+
+	existing, err := os.Open(destPath)
+	if err != nil {
+		return false, err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(existing)
+
+	generated, err := file.open()
+	if err != nil {
+		return false, err
+	}
+	defer func(r io.ReadCloser) {
+		_ = r.Close()
+	}(generated)
+
+	a, b := make([]byte, 32*1024), make([]byte, 32*1024)
+	for {
+		n, errA := io.ReadFull(existing, a)
+		m, errB := io.ReadFull(generated, b)
+		if n != m || !bytes.Equal(a[:n], b[:m]) {
+			return false, nil
+		}
+
+		doneA := errors.Is(errA, io.EOF) || errors.Is(errA, io.ErrUnexpectedEOF)
+		doneB := errors.Is(errB, io.EOF) || errors.Is(errB, io.ErrUnexpectedEOF)
+		switch {
+		case errA != nil && !doneA:
+			return false, errA
+		case errB != nil && !doneB:
+			return false, errB
+		case doneA || doneB:
+			return doneA == doneB, nil
+		}
+	}
+}
+
+// loadTemplateFile produces the generated file for a template file. Binary
+// files are copied verbatim, and large files that need no rendering are
+// streamed when written instead of being read now.
+func loadTemplateFile(fsys fs.FS, relPath, templateModule string, manifest *Manifest, data templateData) (File, error) {
+	info, err := fs.Stat(fsys, relPath)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read template file %s: %w", relPath, err)
+	}
+
+	head, err := readHead(fsys, relPath)
+	if err != nil {
+		return File{}, err
+	}
+
+	file := File{Mode: manifest.fileMode(relPath, info, head), Binary: isBinary(head)}
+
+	if info.Size() >= largeFileSize && (file.Binary || !rendered(relPath)) {
+		file.stream = &streamSource{fsys: fsys, path: relPath, size: info.Size()}
+		return file, nil
+	}
+
+	if file.Binary {
+		file.Content, err = fs.ReadFile(fsys, relPath)
+		if err != nil {
+			return File{}, fmt.Errorf("failed to read template file %s: %w", relPath, err)
+		}
+		return file, nil
+	}
+
+	file.Content, err = processTemplateFile(fsys, relPath, templateModule, manifest, data)
+	return file, err
+}
+
+// readHead reads the start of a template file, to sniff its type
+func readHead(fsys fs.FS, relPath string) ([]byte, error) {
+	f, err := fsys.Open(relPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file %s: %w", relPath, err)
+	}
+	defer func(f fs.File) {
+		_ = f.Close()
+	}(f)
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read template file %s: %w", relPath, err)
+	}
+	return head[:n], nil
+}
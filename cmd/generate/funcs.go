@@ -0,0 +1,88 @@
+package generate
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+)
+
+// templateFuncs are the functions available in template files and variable defaults.
+// Functions taking the value to transform take it last, so they can be used in pipelines:
+// {{ .Vars.table | plural | snake }}
+var templateFuncs = template.FuncMap{
+	// Case conversions
+	"pascal":         utils.ToPascalCase,
+	"camel":          utils.ToCamelCase,
+	"snake":          utils.ToSnakeCase,
+	"kebab":          utils.ToKebabCase,
+	"screamingSnake": utils.ToScreamingSnakeCase,
+	"title":          utils.ToTitleCase,
+	"lower":          strings.ToLower,
+	"upper":          strings.ToUpper,
+
+	// Inflection
+	"plural":   utils.Pluralize,
+	"singular": utils.Singularize,
+
+	// Strings
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+	"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+	"indent":     utils.Indent,
+	"goIdent":    utils.ToGoIdentifier,
+
+	// Dates
+	"now":  time.Now,
+	"date": func(layout string, t time.Time) string { return t.Format(layout) },
+
+	// Random values, different on every render
+	"secret": utils.RandomSecret,
+	"uuid":   utils.NewUUID,
+
+	// Environment and defaults
+	"env":      os.Getenv,
+	"default":  defaultValue,
+	"required": requiredValue,
+}
+
+// defaultValue returns value, or def when value is empty
+func defaultValue(def, value any) any {
+	if isEmpty(value) {
+		return def
+	}
+	return value
+}
+
+// requiredValue fails rendering with message when value is empty
+func requiredValue(message string, value any) (any, error) {
+	if isEmpty(value) {
+		return nil, errors.New(message)
+	}
+	return value, nil
+}
+
+// isEmpty reports whether value is nil, a zero value or an empty collection
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
@@ -3,101 +3,522 @@ package generate
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"embed"
 	"fmt"
 	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"text/template"
+	"time"
 
+	"github.com/ironlabsdev/iron/internal/config"
 	"github.com/ironlabsdev/iron/internal/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 const (
-	ColorGreen = "\033[32m"
-	ColorBlue  = "\033[34m"
-	ColorReset = "\033[0m"
+	ColorRed    = "\033[31m"
+	ColorGreen  = "\033[32m"
+	ColorYellow = "\033[33m"
+	ColorBlue   = "\033[34m"
+	ColorReset  = "\033[0m"
 )
 
 //go:embed all:templates/*
 var TemplatesFS embed.FS
 
+var (
+	nameOverride string
+	modulePath   string
+	dryRun       string
+	force        bool
+	skipExisting bool
+	merge        bool
+	templateDir  string
+	setValues    []string
+	noInput      bool
+	withFeatures []string
+	without      []string
+	skipHooks    bool
+	output       string
+)
+
 // GenerateCmd is the base command.
 var GenerateCmd = &cobra.Command{
-	Use:   "generate",
+	Use:   "generate [template] [project-name]",
 	Short: "Generate code from templates",
 	Long: `Generate code scaffolding from predefined templates.
 
 Available templates:
-  oauth   - OAuth authentication implementation`,
-	Run: func(cmd *cobra.Command, args []string) {
-		_ = cmd.Usage()
+%s
+Templates are looked up in --template-dir, then in the directories listed
+under templates.paths in the config file, then in the templates built into
+iron. Any template found this way can be generated with:
+
+  iron generate <template> <project-name>
+
+Template variables are set with --set name=value. Variables that are not
+set are prompted for when running on a terminal, unless --no-input is given.
+Optional template features are toggled with --with and --without.
+Post-generation hooks declared by the template, such as go mod tidy, run in
+the new project unless --skip-hooks is given.
+
+Run iron template list to see every available template, including the ones
+from template directories.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch len(args) {
+		case 0:
+			return cmd.Usage()
+		case 1:
+			return fmt.Errorf("missing project name - usage: iron generate %s <project-name>", args[0])
+		}
+
+		return runTemplate(args[0], args[1])
 	},
 }
 
+func init() {
+	GenerateCmd.Long = fmt.Sprintf(GenerateCmd.Long, availableTemplates())
+
+	addTemplateFlags(GenerateCmd)
+}
+
+// addTemplateFlags adds the flags of project generation to cmd. They are not
+// persistent flags of GenerateCmd, so the component commands do not inherit
+// flags they would ignore.
+func addTemplateFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&nameOverride, "name", "", "Project name used in the generated files (default the name of the target directory)")
+	flags.StringVarP(&modulePath, "module", "m", "", "Go module path of the generated project (default derived from the project name)")
+	flags.StringVar(&dryRun, "dry-run", "", "Preview the generated project without writing files (tree, content or txtar)")
+	flags.Lookup("dry-run").NoOptDefVal = DryRunTree
+	flags.BoolVar(&force, "force", false, "Overwrite existing files, keeping a .orig backup")
+	flags.BoolVar(&skipExisting, "skip-existing", false, "Keep existing files untouched")
+	flags.BoolVar(&merge, "merge", false, "Merge into existing files that differ, with conflict markers around the lines that differ")
+	flags.StringVar(&templateDir, "template-dir", "", "Directory to load templates from before the configured and built-in ones")
+	flags.StringArrayVar(&setValues, "set", nil, "Set a template variable (name=value, repeatable)")
+	flags.StringSliceVar(&withFeatures, "with", nil, "Enable optional template features (comma separated)")
+	flags.StringSliceVar(&without, "without", nil, "Disable optional template features (comma separated)")
+	flags.BoolVar(&skipHooks, "skip-hooks", false, "Do not run the post-generation hooks declared by the template")
+	flags.BoolVar(&noInput, "no-input", false, "Never prompt for template variables, fail if a required one is missing")
+	flags.StringVarP(&output, "output", "o", OutputText, "Output format of the generation summary: text or json")
+	cmd.MarkFlagsMutuallyExclusive("force", "skip-existing", "merge")
+}
+
+// runTemplate generates templateName into target, resolved against the working directory
+func runTemplate(templateName, target string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+
+	var fullPath string
+
+	if target == "." {
+		fullPath = cwd
+	} else {
+		fullPath = filepath.Join(cwd, target)
+	}
+
+	fullPath = filepath.Clean(fullPath)
+
+	values, err := parseSetFlags(setValues)
+	if err != nil {
+		return err
+	}
+
+	features, err := parseFeatureFlags(withFeatures, without)
+	if err != nil {
+		return err
+	}
+
+	return FromTemplate(templateName, fullPath, Options{
+		ProjectName:  nameOverride,
+		Values:       values,
+		Prompter:     newPrompter(noInput),
+		Features:     features,
+		SkipHooks:    skipHooks,
+		ModulePath:   modulePath,
+		DryRun:       dryRun,
+		Output:       output,
+		Conflict:     conflictStrategy(),
+		TemplateDirs: templateDirs(),
+	})
+}
+
+// templateDirs lists the template directories from --template-dir and the config file
+func templateDirs() []string {
+	return searchDirs(templateDir, viper.GetStringSlice(config.TemplatePathsKey), os.Stderr)
+}
+
+// availableTemplates describes the embedded templates using their manifests
+func availableTemplates() string {
+	sources, err := Sources(nil)
+	if err != nil {
+		return ""
+	}
+
+	summaries, err := ListTemplates(sources)
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, s := range summaries {
+		description := s.Description
+		if s.Error != "" {
+			description = "(invalid manifest)"
+		}
+		_, _ = fmt.Fprintf(&b, "  %-8s- %s\n", s.Name, description)
+	}
+
+	return b.String()
+}
+
+// conflictStrategy maps the conflict flags to a ConflictStrategy
+func conflictStrategy() ConflictStrategy {
+	switch {
+	case force:
+		return ConflictForce
+	case skipExisting:
+		return ConflictSkip
+	case merge:
+		return ConflictMerge
+	}
+	return ConflictAbort
+}
+
+// Options configures how a template is generated
+type Options struct {
+	// ProjectName is the name the project values such as ProjectNameSnake are
+	// derived from. When empty it is the name of the target directory.
+	ProjectName string
+	// ModulePath is the Go module path of the generated project.
+	// When empty it is derived from the project name.
+	ModulePath string
+	// DryRun renders the project in memory and prints a preview instead of
+	// writing files. Valid modes are "tree", "content" and "txtar".
+	DryRun string
+	// Output is the format of the summary printed after generation, text or json.
+	// With json, progress and hook output go to stderr.
+	Output string
+	// Conflict decides how files that already exist in the target directory are handled
+	Conflict ConflictStrategy
+	// Values are the template variables supplied by the user
+	Values map[string]string
+	// Prompter asks for variables missing from Values. When nil, defaults are
+	// used and missing required variables are an error.
+	Prompter Prompter
+	// SkipHooks disables the post-generation hooks declared by the template
+	SkipHooks bool
+	// Features enables or disables optional template features, overriding their defaults
+	Features map[string]bool
+	// TemplateDirs are searched for the template, in order, before the embedded templates
+	TemplateDirs []string
+}
+
+// templateData holds the values available to template files
+type templateData struct {
+	ProjectName      string
+	ProjectNameCamel string
+	ProjectNameSnake string
+	ProjectNameKebab string
+	ModulePath       string
+	// Vars holds the variables declared in the template manifest
+	Vars map[string]any
+	// Features holds the state of the features declared in the template manifest
+	Features map[string]bool
+}
+
+// newTemplateData computes the template values for a project
+func newTemplateData(projectName, modulePath string) templateData {
+	return templateData{
+		ProjectName:      projectName,
+		ProjectNameCamel: utils.ToCamelCase(projectName),
+		ProjectNameSnake: utils.ToSnakeCase(projectName),
+		ProjectNameKebab: utils.ToKebabCase(projectName),
+		ModulePath:       modulePath,
+	}
+}
+
+// File is a rendered template file
+type File struct {
+	// Path is the slash-separated path relative to the project root
+	Path    string
+	Content []byte
+	// Mode holds the permission bits the file is written with
+	Mode fs.FileMode
+	// Binary files are copied from the template verbatim
+	Binary bool
+	// CreateOnly files are only generated when they do not exist yet
+	CreateOnly bool
+	// stream is set instead of Content for large files copied verbatim
+	stream *streamSource
+}
+
+// Project is a template rendered in memory
+type Project struct {
+	Template string
+	// Source is where the template was loaded from
+	Source           string
+	TemplateChecksum string
+	Manifest         *Manifest
+	Data             templateData
+	Files            []File
+}
+
 // FromTemplate copies and processes template files to the specified full path
-func FromTemplate(templateName, fullPath string) error {
-	templatePath := filepath.Join("templates", templateName)
+func FromTemplate(templateName, fullPath string, opts Options) error {
+	if err := validateDryRunMode(opts.DryRun); err != nil {
+		return err
+	}
+	if err := validateOutput(opts.Output, opts.DryRun); err != nil {
+		return err
+	}
+	jsonOutput := opts.Output == OutputJSON
+	start := time.Now()
+
+	sources, err := Sources(opts.TemplateDirs)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := ResolveTemplate(templateName, sources)
+	if err != nil {
+		return err
+	}
 
-	// Check if template exists
-	if _, err := TemplatesFS.ReadDir(templatePath); err != nil {
-		return fmt.Errorf("template '%s' not found", templateName)
+	// Extract project name from the full path for template processing
+	projectName := opts.ProjectName
+	if projectName == "" {
+		projectName = filepath.Base(fullPath)
+	}
+	if err := validateProjectName(projectName, opts.ModulePath == ""); err != nil {
+		return err
+	}
+
+	project, err := Render(tmpl, projectName, opts)
+	if err != nil {
+		return err
 	}
 
 	// Check if directory exists and validate it
-	if err := validateTargetDirectory(fullPath); err != nil {
+	if err := validateTargetDirectory(fullPath, opts.Conflict); err != nil {
 		return err
 	}
 
-	// Create project directory if it doesn't exist
-	if err := os.MkdirAll(fullPath, 0755); err != nil {
-		return fmt.Errorf("failed to create project directory: %w", err)
+	// Detect per-file conflicts with existing content
+	plan, err := planFiles(fullPath, project.Files)
+	if err != nil {
+		return err
 	}
 
-	// Extract project name from the full path for template processing
-	projectName := filepath.Base(fullPath)
+	if opts.DryRun != "" {
+		if err := printDryRun(os.Stdout, project, fullPath, opts.DryRun); err != nil {
+			return err
+		}
+		if opts.DryRun != DryRunTxtar {
+			printPlan(os.Stdout, plan, opts.Conflict)
+			if !opts.SkipHooks {
+				printPendingHooks(os.Stdout, project.Manifest.activeHooks(project.Data.Features))
+			}
+		}
+		return nil
+	}
 
-	// Walk through template directory
-	err := fs.WalkDir(TemplatesFS, templatePath, func(path string, d fs.DirEntry, err error) error {
+	timing := ReportTiming{RenderMs: time.Since(start).Milliseconds()}
+
+	// Files are only moved into the project directory once all of them are
+	// written, so a failure or Ctrl-C leaves it untouched
+	writeStart := time.Now()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	result, lock, err := generateProject(ctx, fullPath, tmpl, project, plan, opts.Conflict)
+	stop()
+	if err != nil {
+		return fmt.Errorf("failed to generate from template: %w", err)
+	}
+	timing.WriteMs = time.Since(writeStart).Milliseconds()
+
+	if !jsonOutput {
+		printResult(os.Stdout, result)
+
+		// Print success message in green with colored icons
+		fmt.Printf("%s✓ Successfully generated %s project in '%s'%s\n", ColorGreen, templateName, fullPath, ColorReset)
+	}
+
+	var hooks []HookResult
+	var hooksErr error
+	if !opts.SkipHooks {
+		hooksStart := time.Now()
+		before, err := projectChecksums(fullPath)
 		if err != nil {
 			return err
 		}
+		if jsonOutput {
+			// Keep stdout for the report
+			hooks = runHooks(context.Background(), fullPath, project.Manifest.activeHooks(project.Data.Features), os.Stderr)
+			hooksErr = hooksError(hooks)
+		} else {
+			hooks = runHooks(context.Background(), fullPath, project.Manifest.activeHooks(project.Data.Features), os.Stdout)
+			hooksErr = printHookReport(os.Stdout, fullPath, hooks)
+		}
+		timing.HooksMs = time.Since(hooksStart).Milliseconds()
 
-		// Skip the root template directory
-		if path == templatePath {
-			return nil
+		// iron diff and iron upgrade must not mistake the hook outputs for edits
+		if err := recordHookOutputs(fullPath, lock, before); err != nil {
+			return err
 		}
+	}
 
-		// Calculate relative path from template root
-		relPath, err := filepath.Rel(templatePath, path)
-		if err != nil {
+	if jsonOutput {
+		timing.TotalMs = time.Since(start).Milliseconds()
+		if err := printJSON(os.Stdout, newReport(fullPath, project, result, lock, hooks, timing)); err != nil {
 			return err
 		}
+		return hooksErr
+	}
+	if hooksErr != nil {
+		return hooksErr
+	}
+
+	fmt.Printf("%s→ Navigate to your project: %scd %s%s\n", ColorBlue, ColorReset, fullPath, ColorReset)
+
+	return nil
+}
 
-		// Handle special file renames
-		destRelPath := handleSpecialFileRenames(relPath)
+// Render processes every template file in memory without touching the disk
+func Render(tmpl *Template, projectName string, opts Options) (*Project, error) {
+	modulePath := opts.ModulePath
+	if modulePath == "" {
+		modulePath = defaultModulePath(projectName)
+	}
+	if err := validateModulePath(modulePath); err != nil {
+		return nil, err
+	}
+
+	templateModule, err := templateModulePath(tmpl.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := LoadManifest(tmpl)
+	if err != nil {
+		return nil, err
+	}
 
-		// Calculate destination path
-		destPath := filepath.Join(fullPath, destRelPath)
+	data := newTemplateData(projectName, modulePath)
+	data.Features, err = manifest.ResolveFeatures(opts.Features)
+	if err != nil {
+		return nil, err
+	}
+	data.Vars, err = manifest.ResolveVars(opts.Values, data, opts.Prompter)
+	if err != nil {
+		return nil, err
+	}
 
-		if d.IsDir() {
-			// Create directory
-			return os.MkdirAll(destPath, 0755)
+	ignore, err := loadIgnore(tmpl.FS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate from template: %w", err)
+	}
+	if err := manifest.checkCreateOnlySources(tmpl.FS); err != nil {
+		return nil, fmt.Errorf("failed to generate from template: %w", err)
+	}
+
+	sum, err := templateChecksum(tmpl.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Project{
+		Template:         tmpl.Name,
+		Source:           tmpl.Source,
+		TemplateChecksum: sum,
+		Manifest:         manifest,
+		Data:             data,
+	}
+
+	// Template path of every rendered file, for error messages
+	var sources []string
+
+	// Walk through template directory
+	err = fs.WalkDir(tmpl.FS, ".", func(relPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Leave out ignored files and everything belonging to disabled features
+		if relPath != "." && (ignore.ignored(relPath, d.IsDir()) || manifest.excluded(relPath, data.Features)) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		// Directories are created implicitly when their files are written,
+		// and the manifest and ignore file only describe the template
+		if d.IsDir() || relPath == ManifestFile || relPath == IgnoreFile {
+			return nil
 		}
 
 		// Process file
-		return processTemplateFile(path, destPath, projectName)
+		file, err := loadTemplateFile(tmpl.FS, relPath, templateModule, manifest, project.Data)
+		if err != nil {
+			return err
+		}
+
+		// Handle special file renames, then render templated names
+		destPath, err := renderPath(handleSpecialFileRenames(relPath), manifest, project.Data)
+		if err != nil {
+			return err
+		}
+
+		file.Path = destPath
+		file.CreateOnly = manifest.createOnly(relPath)
+		project.Files = append(project.Files, file)
+		sources = append(sources, relPath)
+
+		// Files created from this one, e.g. .env from .env.example
+		for _, c := range manifest.createdFrom(relPath) {
+			copyPath, err := renderPath(c.Path, manifest, project.Data)
+			if err != nil {
+				return err
+			}
+
+			created := file
+			created.Path, created.CreateOnly = copyPath, true
+			project.Files = append(project.Files, created)
+			sources = append(sources, relPath)
+		}
+		return nil
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to generate from template: %w", err)
+		return nil, fmt.Errorf("failed to generate from template: %w", err)
 	}
 
-	// Print success message in green with colored icons
-	fmt.Printf("%s✓ Successfully generated %s project in '%s'%s\n", ColorGreen, templateName, fullPath, ColorReset)
-	fmt.Printf("%s→ Navigate to your project: %scd %s%s\n", ColorBlue, ColorReset, fullPath, ColorReset)
+	if err := checkPathCollisions(project.Files, sources); err != nil {
+		return nil, fmt.Errorf("failed to generate from template: %w", err)
+	}
+
+	return project, nil
+}
+
+// writeFile writes content to destPath, creating parent directories as needed
+func writeFile(destPath string, content []byte) error {
+	// Create destination directory
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	if err := os.WriteFile(destPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", destPath, err)
+	}
 
 	return nil
 }
@@ -112,12 +533,19 @@ func handleSpecialFileRenames(relPath string) string {
 		return strings.TrimSuffix(relPath, ".template")
 	}
 
+	// Raw files keep their own name
+	if strings.HasSuffix(relPath, rawSuffix) {
+		return strings.TrimSuffix(relPath, rawSuffix)
+	}
+
 	// You can add more special file handling here if needed
 	return relPath
 }
 
-// validateTargetDirectory checks if the target directory exists and is empty
-func validateTargetDirectory(fullPath string) error {
+// validateTargetDirectory checks if the target directory exists and is empty.
+// Harmless entries such as .git or a README are always allowed, anything else
+// requires a conflict strategy.
+func validateTargetDirectory(fullPath string, strategy ConflictStrategy) error {
 	// Check if directory exists
 	info, err := os.Stat(fullPath)
 	if err != nil {
@@ -139,8 +567,20 @@ func validateTargetDirectory(fullPath string) error {
 		return fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	if len(entries) > 0 {
-		return fmt.Errorf("directory '%s' is not empty - cannot generate code in a non-empty directory", fullPath)
+	if strategy != ConflictAbort {
+		return nil
+	}
+
+	var unexpected []string
+	for _, entry := range entries {
+		if !allowedExistingEntries[entry.Name()] {
+			unexpected = append(unexpected, entry.Name())
+		}
+	}
+
+	if len(unexpected) > 0 {
+		return fmt.Errorf("directory '%s' is not empty (found %s) - use --force, --skip-existing or --merge to generate into it",
+			fullPath, strings.Join(unexpected, ", "))
 	}
 
 	return nil
@@ -171,17 +611,32 @@ func removeBuildTags(content []byte) []byte {
 	return []byte(strings.Join(lines, "\n"))
 }
 
-// processTemplateFile reads, processes, and writes a template file
-func processTemplateFile(srcPath, destPath, projectName string) error {
+// processTemplateFile reads and processes a template file
+func processTemplateFile(fsys fs.FS, srcPath, templateModule string, manifest *Manifest, data templateData) ([]byte, error) {
 	// Read template content
-	content, err := TemplatesFS.ReadFile(srcPath)
+	content, err := fs.ReadFile(fsys, srcPath)
 	if err != nil {
-		return fmt.Errorf("failed to read template file %s: %w", srcPath, err)
+		return nil, fmt.Errorf("failed to read template file %s: %w", srcPath, err)
 	}
 
-	// Create destination directory
-	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
+	// Point go.mod and imports at the project module, template or not
+	content = rewriteModulePath(content, srcPath, templateModule, data.ModulePath)
+
+	// Check if file should be processed as template. Build tags are still in
+	// place so errors point at the right line of the template file.
+	if shouldProcessAsTemplate(srcPath) {
+		var ft fileTemplate
+		ft, content, err = manifest.fileTemplate(srcPath, content)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ft.raw {
+			content, err = processGoTemplate(srcPath, content, ft.left, ft.right, data)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Remove build tags from Go files
@@ -189,25 +644,19 @@ func processTemplateFile(srcPath, destPath, projectName string) error {
 		content = removeBuildTags(content)
 	}
 
-	// Check if file should be processed as template
-	if shouldProcessAsTemplate(srcPath) {
-		return processGoTemplate(content, destPath, projectName)
-	}
-
-	// Copy file as-is
-	return os.WriteFile(destPath, content, 0644)
+	return content, nil
 }
 
 // shouldProcessAsTemplate determines if a file should be processed as a Go template
 func shouldProcessAsTemplate(path string) bool {
-	// Don't process files that end with .template as Go templates
+	// Don't process files that end with .template or .raw as Go templates
 	// They should be renamed and copied as-is
-	if strings.HasSuffix(path, ".template") {
+	if strings.HasSuffix(path, ".template") || strings.HasSuffix(path, rawSuffix) {
 		return false
 	}
 
-	// Process specific file types as templates
-	ext := filepath.Ext(path)
+	// Process specific file types as templates, and examples of them like .env.example
+	ext := filepath.Ext(strings.TrimSuffix(path, ".example"))
 	templateExts := []string{".go", ".mod", ".yaml", ".yml", ".json", ".md", ".txt", ".env"}
 
 	for _, tExt := range templateExts {
@@ -219,40 +668,20 @@ func shouldProcessAsTemplate(path string) bool {
 	return false
 }
 
-// processGoTemplate processes content as a Go template
-func processGoTemplate(content []byte, destPath, projectName string) error {
-	// Create template data
-	data := struct {
-		ProjectName      string
-		ProjectNameCamel string
-		ProjectNameSnake string
-		ProjectNameKebab string
-	}{
-		ProjectName:      projectName,
-		ProjectNameCamel: utils.ToCamelCase(projectName),
-		ProjectNameSnake: utils.ToSnakeCase(projectName),
-		ProjectNameKebab: utils.ToKebabCase(projectName),
-	}
-
+// processGoTemplate processes content as a Go template with the given delimiters,
+// naming it after the template file so errors report file:line
+func processGoTemplate(name string, content []byte, left, right string, data templateData) ([]byte, error) {
 	// Parse and execute template
-	tmpl, err := template.New("template").Parse(string(content))
+	tmpl, err := template.New(name).Delims(left, right).Funcs(templateFuncs).Parse(string(content))
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	// Create destination file
-	file, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer func(file *os.File) {
-		_ = file.Close()
-	}(file)
-
 	// Execute template
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return nil
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,42 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerateIntoClone scaffolds into a freshly cloned repository, whose
+// .gitignore differs from the template's
+func TestGenerateIntoClone(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "billing")
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	clone := map[string]string{
+		".gitignore": "*.exe\n",
+		"README.md":  "# billing\n",
+		"LICENSE":    "MIT\n",
+	}
+	for name, content := range clone {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := FromTemplate("oauth", dir, Options{SkipHooks: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range clone {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q (%v), want it kept as %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"main.go", LockfilePath} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s was not generated: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package generate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/routes"
+	"github.com/ironlabsdev/iron/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Layout of projects generated from the oauth template
+const (
+	pagesDir       = "web/pages"
+	pagesType      = "Pages"
+	viewsDir       = "views/pages"
+	requestlogPath = "web/requestlog"
+)
+
+var (
+	handlerMethod     string
+	handlerPath       string
+	handlerType       string
+	handlerProjectDir string
+	handlerDryRun     bool
+	handlerForce      bool
+)
+
+// HandlerCmd adds a page handler to an existing project
+var HandlerCmd = &cobra.Command{
+	Use:   "handler <name>",
+	Short: "Add a page handler, its view and its route to an existing project",
+	Long: `Add a page to a project generated from the oauth template.
+
+Creates:
+  web/pages/<name>.go         a handler method on *pages.Pages, or on the
+                              handler type given with --type
+  web/pages/<name>_test.go    a test rendering the handler
+  views/pages/<name>.templ    the templ view rendered by the handler
+
+and registers the route in Controller.RegisterRoutes of web/router, wrapped
+with requestlog.NewHandler. The module path is read from go.mod. When templ is
+installed, templ generate is run for the new view.
+
+Example:
+  iron generate handler reports --method GET --path /reports`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return AddHandler(HandlerOptions{
+			Name:       args[0],
+			Method:     handlerMethod,
+			Path:       handlerPath,
+			Type:       handlerType,
+			ProjectDir: handlerProjectDir,
+			DryRun:     handlerDryRun,
+			Force:      handlerForce,
+		})
+	},
+}
+
+func init() {
+	HandlerCmd.Flags().StringVar(&handlerMethod, "method", "GET", "HTTP method of the route")
+	HandlerCmd.Flags().StringVar(&handlerPath, "path", "", "Path of the route (default /<name> in kebab case)")
+	HandlerCmd.Flags().StringVar(&handlerType, "type", pagesType, "Handler type the method is added to, created in web/pages when it does not exist")
+	HandlerCmd.Flags().StringVarP(&handlerProjectDir, "dir", "C", ".", "Directory of the project")
+	HandlerCmd.Flags().BoolVar(&handlerDryRun, "dry-run", false, "Show the files that would be created and changed without writing them")
+	HandlerCmd.Flags().BoolVar(&handlerForce, "force", false, "Overwrite existing handler, test and view files")
+	GenerateCmd.AddCommand(HandlerCmd)
+}
+
+// HandlerOptions configures AddHandler
+type HandlerOptions struct {
+	// Name of the handler, e.g. reports or user-settings
+	Name   string
+	Method string
+	// Path of the route. Defaults to /<name> in kebab case.
+	Path string
+	// Type is the handler type the method is added to. Defaults to Pages.
+	Type       string
+	ProjectDir string
+	DryRun     bool
+	Force      bool
+}
+
+// handlerData is the data of the handler component skeletons
+type handlerData struct {
+	ModulePath string
+	// Name is the handler method and view component, e.g. UserSettings
+	Name string
+	// Title is the human-readable name, e.g. User Settings
+	Title       string
+	Type        string
+	NewType     bool
+	Receiver    string
+	Method      string
+	MethodConst string
+	Path        string
+}
+
+// templComponentRe finds the components declared in .templ files
+var templComponentRe = regexp.MustCompile(`(?m)^templ\s+([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+// AddHandler adds a handler, its test, its view and its route to an existing project
+func AddHandler(opts HandlerOptions) error {
+	project, err := findProject(opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	if err := project.require(path.Join(pagesDir, "pages.go"), viewsDir); err != nil {
+		return err
+	}
+
+	data, err := newHandlerData(project, opts)
+	if err != nil {
+		return err
+	}
+
+	snake := utils.ToSnakeCase(opts.Name)
+	handlerFile := path.Join(pagesDir, snake+".go")
+	testFile := path.Join(pagesDir, snake+"_test.go")
+	viewFile := path.Join(viewsDir, snake+".templ")
+
+	// Files about to be overwritten with --force do not count as conflicts
+	replaced := map[string]bool{}
+	if opts.Force {
+		replaced[handlerFile], replaced[testFile], replaced[viewFile] = true, true, true
+		replaced[path.Join(viewsDir, snake+"_templ.go")] = true
+	}
+
+	types, methods, err := handlerDeclarations(project, replaced)
+	if err != nil {
+		return err
+	}
+	data.NewType = !types[data.Type]
+	if methods[data.Type+"."+data.Name] {
+		return fmt.Errorf("%s already has a %s method", data.Type, data.Name)
+	}
+
+	components, err := viewComponents(project, replaced)
+	if err != nil {
+		return err
+	}
+	if components[data.Name] {
+		return fmt.Errorf("a view component called %s already exists in %s", data.Name, viewsDir)
+	}
+
+	router, routerFile, err := addHandlerRoute(project, data)
+	if err != nil {
+		return err
+	}
+
+	var changes []componentChange
+	for _, c := range []struct{ skeleton, relPath string }{
+		{"handler/handler.go.tmpl", handlerFile},
+		{"handler/handler_test.go.tmpl", testFile},
+		{"handler/view.templ.tmpl", viewFile},
+	} {
+		content, err := renderComponent(c.skeleton, data)
+		if err != nil {
+			return err
+		}
+		change, err := project.newComponentChange(c.relPath, content, opts.Force)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, change)
+	}
+
+	change, err := project.newComponentChange(routerFile, router, true)
+	if err != nil {
+		return err
+	}
+	changes = append(changes, change)
+
+	if opts.DryRun {
+		fmt.Printf("%sDry run: %s %s handled by %s.%s (no files written)%s\n\n", ColorBlue, data.Method, data.Path, data.Type, data.Name, ColorReset)
+		printComponentChanges(os.Stdout, changes, true)
+		return nil
+	}
+
+	if err := project.applyComponentChanges(changes); err != nil {
+		return fmt.Errorf("failed to add handler: %w", err)
+	}
+
+	printComponentChanges(os.Stdout, changes, false)
+	fmt.Printf("%s✓ Added %s %s handled by %s.%s%s\n", ColorGreen, data.Method, data.Path, data.Type, data.Name, ColorReset)
+
+	project.runTool(templInstall, "templ", "generate", "-f", viewFile)
+	return nil
+}
+
+// newHandlerData validates the options and derives the skeleton data
+func newHandlerData(project *existingProject, opts HandlerOptions) (handlerData, error) {
+	name := utils.ToPascalCase(opts.Name)
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return handlerData{}, fmt.Errorf("invalid handler name '%s' - use letters, digits, dashes or underscores, starting with a letter", opts.Name)
+	}
+
+	typeName := utils.ToPascalCase(opts.Type)
+	if opts.Type == "" {
+		typeName = pagesType
+	}
+	if !token.IsIdentifier(typeName) || !token.IsExported(typeName) {
+		return handlerData{}, fmt.Errorf("invalid handler type '%s'", opts.Type)
+	}
+
+	method := strings.ToUpper(opts.Method)
+	if !routes.ValidMethod(method) {
+		return handlerData{}, fmt.Errorf("invalid HTTP method '%s'", opts.Method)
+	}
+
+	routePath := opts.Path
+	if routePath == "" {
+		routePath = "/" + utils.ToKebabCase(opts.Name)
+	}
+	if !strings.HasPrefix(routePath, "/") {
+		return handlerData{}, fmt.Errorf("invalid route path '%s' - it must start with /", routePath)
+	}
+
+	// The receiver must not shadow the w and r parameters of the handler
+	receiver := strings.ToLower(typeName[:1])
+	if receiver == "w" || receiver == "r" {
+		receiver = "h"
+	}
+
+	return handlerData{
+		ModulePath:  project.ModulePath,
+		Name:        name,
+		Title:       utils.ToTitleCase(opts.Name),
+		Type:        typeName,
+		Receiver:    receiver,
+		Method:      method,
+		MethodConst: "http.Method" + utils.ToPascalCase(strings.ToLower(method)),
+		Path:        routePath,
+	}, nil
+}
+
+// handlerDeclarations lists the types and the Type.Method methods declared in
+// web/pages, leaving out the files in skip
+func handlerDeclarations(project *existingProject, skip map[string]bool) (map[string]bool, map[string]bool, error) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(project.path(pagesDir))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", pagesDir, err)
+	}
+
+	types, methods := map[string]bool{}, map[string]bool{}
+	for _, entry := range entries {
+		relPath := path.Join(pagesDir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || skip[relPath] {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, project.path(relPath), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", relPath, err)
+		}
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						types[ts.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) != 1 {
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					methods[ident.Name+"."+d.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	return types, methods, nil
+}
+
+// viewComponents lists the templ components declared in views/pages, leaving out the files in skip
+func viewComponents(project *existingProject, skip map[string]bool) (map[string]bool, error) {
+	entries, err := os.ReadDir(project.path(viewsDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", viewsDir, err)
+	}
+
+	components := map[string]bool{}
+	for _, entry := range entries {
+		relPath := path.Join(viewsDir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".templ") || skip[relPath] {
+			continue
+		}
+
+		content, err := os.ReadFile(project.path(relPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+		for _, match := range templComponentRe.FindAllSubmatch(content, -1) {
+			components[string(match[1])] = true
+		}
+	}
+
+	return components, nil
+}
+
+// addHandlerRoute registers the handler in RegisterRoutes and returns the new
+// router source with the project path of the router file
+func addHandlerRoute(project *existingProject, data handlerData) ([]byte, string, error) {
+	router, routerFile, err := project.router()
+	if err != nil {
+		return nil, "", err
+	}
+
+	constructor := "pages.New" + data.Type
+	handlerVar, ok := router.HandlerVar(constructor)
+	if !ok {
+		if !data.NewType {
+			return nil, "", fmt.Errorf("%s does not create a %s handler with %s - add it to %s first", routerFile, data.Type, constructor, routes.RoutesFunc)
+		}
+		handlerVar = utils.ToCamelCase(data.Type) + "Handler"
+		if err := router.DeclareHandler(handlerVar, constructor, "Logger"); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := router.Add(routes.Route{Method: data.Method, Path: data.Path, Handler: handlerVar + "." + data.Name}); err != nil {
+		return nil, "", err
+	}
+
+	for _, importPath := range []string{"net/http", project.ModulePath + "/" + pagesDir, project.ModulePath + "/" + requestlogPath} {
+		if err := router.AddImport(importPath); err != nil {
+			return nil, "", err
+		}
+	}
+
+	content, err := router.Bytes()
+	return content, routerFile, err
+}
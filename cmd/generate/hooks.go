@@ -0,0 +1,261 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultHookTimeout bounds hooks that do not declare a timeout
+const defaultHookTimeout = 5 * time.Minute
+
+// Hook is a command run in the generated project once all files are written
+type Hook struct {
+	Name string `yaml:"name"`
+	// Run is the command and its arguments. It is executed directly, not through a shell.
+	Run []string `yaml:"run"`
+	// Timeout is a duration such as 30s or 2m
+	Timeout string `yaml:"timeout"`
+	// Install tells the user how to get the tool when it is missing
+	Install string `yaml:"install"`
+	// Feature restricts the hook to projects with that feature enabled
+	Feature string `yaml:"feature"`
+
+	timeout time.Duration
+}
+
+// Hook outcomes
+const (
+	HookOK      = "ok"
+	HookFailed  = "failed"
+	HookSkipped = "skipped"
+)
+
+// HookResult records what happened when running a hook
+type HookResult struct {
+	Hook     Hook
+	Status   string
+	ExitCode int
+	Err      error
+	Duration time.Duration
+}
+
+// validateHooks checks the hook declarations of the manifest
+func (m *Manifest) validateHooks() error {
+	for i := range m.Hooks {
+		h := &m.Hooks[i]
+
+		if len(h.Run) == 0 {
+			return fmt.Errorf("hook '%s' has no command to run", h.Name)
+		}
+		if h.Name == "" {
+			h.Name = strings.Join(h.Run, " ")
+		}
+
+		h.timeout = defaultHookTimeout
+		if h.Timeout != "" {
+			timeout, err := time.ParseDuration(h.Timeout)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("hook '%s' has an invalid timeout '%s'", h.Name, h.Timeout)
+			}
+			h.timeout = timeout
+		}
+
+		if h.Feature != "" {
+			if _, ok := m.Feature(h.Feature); !ok {
+				return fmt.Errorf("hook '%s' depends on unknown feature '%s'", h.Name, h.Feature)
+			}
+		}
+	}
+
+	return nil
+}
+
+// activeHooks returns the hooks that apply to the enabled features
+func (m *Manifest) activeHooks(features map[string]bool) []Hook {
+	var hooks []Hook
+	for _, h := range m.Hooks {
+		if h.Feature == "" || features[h.Feature] {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// command returns the hook command line as the user would type it
+func (h Hook) command() string {
+	return strings.Join(h.Run, " ")
+}
+
+// runHooks runs the hooks in order inside dir, streaming their output to out.
+// A failing hook does not stop the following ones.
+func runHooks(ctx context.Context, dir string, hooks []Hook, out io.Writer) []HookResult {
+	results := make([]HookResult, 0, len(hooks))
+
+	for _, h := range hooks {
+		if _, err := exec.LookPath(h.Run[0]); err != nil {
+			results = append(results, HookResult{Hook: h, Status: HookSkipped, Err: fmt.Errorf("%s not found in PATH", h.Run[0])})
+			continue
+		}
+
+		_, _ = fmt.Fprintf(out, "%s→ Running %s%s\n", ColorBlue, h.Name, ColorReset)
+		results = append(results, runHook(ctx, dir, h, out))
+	}
+
+	return results
+}
+
+// runHook runs a single hook with its timeout
+func runHook(ctx context.Context, dir string, h Hook, out io.Writer) HookResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Run[0], h.Run[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.Stdin = os.Stdin
+
+	start := time.Now()
+	err := cmd.Run()
+	result := HookResult{Hook: h, Status: HookOK, Duration: time.Since(start)}
+
+	if err == nil {
+		return result
+	}
+
+	result.Status = HookFailed
+	result.Err = err
+	result.ExitCode = -1
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Err = fmt.Errorf("timed out after %s", h.timeout)
+	}
+
+	return result
+}
+
+// projectChecksums returns the SHA-256 of every regular file of the project
+// outside .git and .iron, keyed by slash-separated path
+func projectChecksums(fullPath string) (map[string]string, error) {
+	sums := map[string]string{}
+	err := filepath.WalkDir(fullPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || filePath == filepath.Join(fullPath, ".iron") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(fullPath, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		sums[filepath.ToSlash(relPath)] = checksum(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project files: %w", err)
+	}
+	return sums, nil
+}
+
+// hookOutputs returns the files the hooks created or rewrote with their new
+// checksum, given the project checksums from before and after the hooks ran
+func hookOutputs(before, after map[string]string) map[string]string {
+	outputs := map[string]string{}
+	for relPath, sum := range after {
+		if before[relPath] != sum {
+			outputs[relPath] = sum
+		}
+	}
+	return outputs
+}
+
+// recordHookOutputs rewrites the lockfile with the files the hooks created or
+// rewrote, given the project checksums from before the hooks ran
+func recordHookOutputs(fullPath string, lock *Lockfile, before map[string]string) error {
+	after, err := projectChecksums(fullPath)
+	if err != nil {
+		return err
+	}
+
+	lock.HookFiles = hookOutputs(before, after)
+	return writeLockfile(fullPath, lock)
+}
+
+// printHookReport summarises the hooks and explains how to rerun the ones that did not succeed.
+// It returns an error when a hook failed.
+func printHookReport(w io.Writer, dir string, results []HookResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w, "\nPost-generation hooks:")
+	for _, r := range results {
+		switch r.Status {
+		case HookOK:
+			_, _ = fmt.Fprintf(w, "  %s✓ %s%s (%s)\n", ColorGreen, r.Hook.Name, ColorReset, r.Duration.Round(time.Millisecond))
+		case HookSkipped:
+			_, _ = fmt.Fprintf(w, "  %s- %s skipped: %v%s\n", ColorYellow, r.Hook.Name, r.Err, ColorReset)
+			if r.Hook.Install != "" {
+				_, _ = fmt.Fprintf(w, "    install: %s\n", r.Hook.Install)
+			}
+			_, _ = fmt.Fprintf(w, "    then run: cd %s && %s\n", dir, r.Hook.command())
+		case HookFailed:
+			_, _ = fmt.Fprintf(w, "  %s✗ %s failed: %v%s\n", ColorRed, r.Hook.Name, r.Err, ColorReset)
+			_, _ = fmt.Fprintf(w, "    rerun with: cd %s && %s\n", dir, r.Hook.command())
+		}
+	}
+
+	return hooksError(results)
+}
+
+// hooksError returns an error when a hook failed
+func hooksError(results []HookResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Status == HookFailed {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d post-generation hooks failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// printPendingHooks lists the hooks a generation would run
+func printPendingHooks(w io.Writer, hooks []Hook) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "\nPost-generation hooks:")
+	for _, h := range hooks {
+		_, _ = fmt.Fprintf(w, "  → %s\n", h.command())
+	}
+}
@@ -0,0 +1,133 @@
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+)
+
+// IgnoreFile lists template files that are never generated, one gitignore-style pattern per line
+const IgnoreFile = ".ironignore"
+
+// ignoreRule is a single pattern of an ignore file
+type ignoreRule struct {
+	pattern string
+	// negate re-includes paths matched by earlier patterns
+	negate bool
+	// dirOnly patterns end in a slash and only match directories
+	dirOnly bool
+}
+
+// ignoreRules are the patterns of an ignore file, in order. The last matching
+// pattern decides whether a path is ignored.
+type ignoreRules []ignoreRule
+
+// loadIgnore reads the ignore file of a template, if it has one
+func loadIgnore(fsys fs.FS) (ignoreRules, error) {
+	content, err := fs.ReadFile(fsys, IgnoreFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+
+	rules, err := parseIgnore(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", IgnoreFile, err)
+	}
+	return rules, nil
+}
+
+// parseIgnore parses gitignore-style patterns. Patterns without a slash match
+// at any depth, others are relative to the template root.
+func parseIgnore(content []byte) (ignoreRules, error) {
+	var rules ignoreRules
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimRight(scanner.Text(), " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\#`) || strings.HasPrefix(pattern, `\!`) {
+			pattern = pattern[1:]
+		}
+
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+
+		if strings.Contains(pattern, "/") {
+			pattern = strings.TrimPrefix(pattern, "/")
+		} else {
+			pattern = "**/" + pattern
+		}
+
+		if pattern == "" || pattern == "**/" {
+			return nil, fmt.Errorf("line %d: empty pattern", line)
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("line %d: invalid pattern '%s': %w", line, scanner.Text(), err)
+			}
+		}
+
+		rule.pattern = pattern
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// ignored reports whether the slash-separated template path is ignored.
+// Files inside ignored directories are not checked separately, so callers
+// walking the template must skip ignored directories.
+func (r ignoreRules) ignored(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if utils.MatchGlob(rule.pattern, relPath) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// walkTemplateFiles calls fn for every file of a template that is not ignored by its ignore file
+func walkTemplateFiles(fsys fs.FS, fn func(relPath string, d fs.DirEntry) error) error {
+	ignore, err := loadIgnore(fsys)
+	if err != nil {
+		return err
+	}
+
+	return fs.WalkDir(fsys, ".", func(relPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if relPath != "." && ignore.ignored(relPath, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return fn(relPath, d)
+	})
+}
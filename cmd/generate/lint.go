@@ -0,0 +1,557 @@
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+)
+
+// TemplateCmd groups the commands working on templates themselves
+var TemplateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"templates"},
+	Short:   "Work with project templates",
+}
+
+// LintCmd validates a template
+var LintCmd = &cobra.Command{
+	Use:   "lint <dir|name>",
+	Short: "Validate a template before shipping it",
+	Long: `Validate a template directory, or a template found by name, before shipping it.
+
+The following checks are run:
+  template    every file processed as a Go template parses
+  build-tags  every Go file carries the same //go:build ignore constraint
+  templ       generated _templ.go files match their .templ sources and the
+              templ version required by the template's go.mod
+  types       the template renders with sample variables and the generated
+              Go code type-checks
+
+Imports of third-party packages are not resolved, so code using them is only
+checked as far as it can be without their sources.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tmpl, err := lintTarget(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%sLinting template '%s' (%s)%s\n", ColorBlue, tmpl.Name, tmpl.Source, ColorReset)
+
+		issues, err := Lint(tmpl)
+		if err != nil {
+			return err
+		}
+
+		errorCount := 0
+		for _, issue := range issues {
+			if issue.Warning {
+				fmt.Printf("%s  ! %s%s\n", ColorYellow, issue, ColorReset)
+				continue
+			}
+			errorCount++
+			fmt.Printf("%s  ✗ %s%s\n", ColorRed, issue, ColorReset)
+		}
+
+		if errorCount > 0 {
+			return fmt.Errorf("found %d problems in template '%s'", errorCount, tmpl.Name)
+		}
+
+		fmt.Printf("%s✓ Template '%s' passed all checks%s\n", ColorGreen, tmpl.Name, ColorReset)
+		return nil
+	},
+}
+
+func init() {
+	LintCmd.Flags().StringVar(&templateDir, "template-dir", "", "Directory to load templates from before the configured and built-in ones")
+	TemplateCmd.AddCommand(LintCmd)
+}
+
+// lintTarget opens arg as a template directory, or resolves it as a template name
+func lintTarget(arg string) (*Template, error) {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		dir, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve template directory: %w", err)
+		}
+		return &Template{Name: filepath.Base(dir), Source: dir, FS: os.DirFS(dir)}, nil
+	}
+
+	sources, err := Sources(templateDirs())
+	if err != nil {
+		return nil, err
+	}
+	return ResolveTemplate(arg, sources)
+}
+
+// LintIssue is a problem found in a template
+type LintIssue struct {
+	// Check is the name of the check reporting the issue
+	Check string
+	Path  string
+	// Line is 0 when the issue concerns the whole file
+	Line    int
+	Message string
+	// Warning issues do not fail the lint
+	Warning bool
+}
+
+func (i LintIssue) String() string {
+	location := i.Path
+	if i.Line > 0 {
+		location += ":" + strconv.Itoa(i.Line)
+	}
+	return fmt.Sprintf("%s: %s [%s]", location, i.Message, i.Check)
+}
+
+var (
+	// templateErrorRe finds the file, line and message of a text/template error
+	templateErrorRe = regexp.MustCompile(`template: ([^:]+):(\d+):(?:\d+:)? (.*)$`)
+	// templVersionRe matches the version header of files generated by templ
+	templVersionRe = regexp.MustCompile(`(?m)^// templ: version: (\S+)`)
+	// majorVersionRe matches the major version suffix of a module path
+	majorVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// templModule is the module providing the templ runtime
+const templModule = "github.com/a-h/templ"
+
+// Lint runs every check against tmpl and returns the issues sorted by file
+func Lint(tmpl *Template) ([]LintIssue, error) {
+	var issues []LintIssue
+
+	if _, err := loadIgnore(tmpl.FS); err != nil {
+		return []LintIssue{{Check: "ignore", Path: IgnoreFile, Message: err.Error()}}, nil
+	}
+
+	// Ignored files are never generated, so they are not checked either
+	var files []string
+	err := walkTemplateFiles(tmpl.FS, func(relPath string, d fs.DirEntry) error {
+		files = append(files, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template '%s': %w", tmpl.Name, err)
+	}
+
+	manifest, err := LoadManifest(tmpl)
+	if err != nil {
+		issues = append(issues, LintIssue{Check: "manifest", Path: ManifestFile, Message: err.Error()})
+	}
+
+	parseIssues, err := lintTemplateSyntax(tmpl.FS, files, manifest)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, parseIssues...)
+
+	tagIssues, err := lintBuildTags(tmpl.FS, files)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, tagIssues...)
+
+	templIssues, err := lintTempl(tmpl.FS, files)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, templIssues...)
+
+	// Rendering needs a valid manifest and templates that parse
+	if manifest != nil && len(parseIssues) == 0 {
+		issues = append(issues, lintTypes(tmpl, manifest)...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues, nil
+}
+
+// lintTemplateSyntax parses every file that is processed as a Go template
+func lintTemplateSyntax(fsys fs.FS, files []string, manifest *Manifest) ([]LintIssue, error) {
+	var issues []LintIssue
+
+	if manifest == nil {
+		manifest = &Manifest{}
+	}
+
+	left, right := manifest.pathDelims()
+	for _, file := range files {
+		if strings.Contains(file, left) {
+			if _, err := template.New(file).Delims(left, right).Funcs(templateFuncs).Parse(file); err != nil {
+				issues = append(issues, LintIssue{Check: "template", Path: file, Message: "invalid path template: " + err.Error()})
+			}
+		}
+
+		if file == ManifestFile || !shouldProcessAsTemplate(file) {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file %s: %w", file, err)
+		}
+
+		ft, content, err := manifest.fileTemplate(file, content)
+		if err != nil {
+			issues = append(issues, LintIssue{Check: "template", Path: file, Line: 1, Message: err.Error()})
+			continue
+		}
+		if ft.raw {
+			continue
+		}
+
+		if _, err := template.New(file).Delims(ft.left, ft.right).Funcs(templateFuncs).Parse(string(content)); err != nil {
+			issues = append(issues, templateIssue("template", file, err))
+		}
+	}
+
+	return issues, nil
+}
+
+// templateIssue converts an error into an issue, keeping the file and line of text/template errors
+func templateIssue(check, file string, err error) LintIssue {
+	issue := LintIssue{Check: check, Path: file, Message: err.Error()}
+
+	if m := templateErrorRe.FindStringSubmatch(err.Error()); m != nil {
+		issue.Path = m[1]
+		issue.Line, _ = strconv.Atoi(m[2])
+		issue.Message = m[3]
+	}
+
+	return issue
+}
+
+// lintBuildTags checks that the Go files of the template agree on their build constraint.
+// Template Go files live inside iron's own module and must be excluded from its build.
+func lintBuildTags(fsys fs.FS, files []string) ([]LintIssue, error) {
+	var (
+		issues   []LintIssue
+		untagged []string
+		tagged   int
+	)
+
+	for _, file := range files {
+		if path.Ext(file) != ".go" {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file %s: %w", file, err)
+		}
+
+		constraint, line := buildConstraint(content)
+		switch constraint {
+		case "":
+			untagged = append(untagged, file)
+		case "ignore":
+			tagged++
+		default:
+			issues = append(issues, LintIssue{Check: "build-tags", Path: file, Line: line,
+				Message: fmt.Sprintf("unexpected build constraint '%s', template Go files use //go:build ignore", constraint)})
+		}
+	}
+
+	if tagged == 0 {
+		return issues, nil
+	}
+
+	for _, file := range untagged {
+		issues = append(issues, LintIssue{Check: "build-tags", Path: file, Line: 1,
+			Message: fmt.Sprintf("missing //go:build ignore (carried by %d other Go files of the template)", tagged)})
+	}
+
+	return issues, nil
+}
+
+// buildConstraint returns the //go:build expression of a Go file and its line
+func buildConstraint(content []byte) (string, int) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "package ") {
+			break
+		}
+		if expr, ok := strings.CutPrefix(text, "//go:build "); ok {
+			return strings.TrimSpace(expr), line
+		}
+	}
+	return "", 0
+}
+
+// lintTempl checks the generated _templ.go files against their sources and
+// the templ version the template's go.mod requires
+func lintTempl(fsys fs.FS, files []string) ([]LintIssue, error) {
+	var issues []LintIssue
+
+	required := requiredVersion(fsys, templModule)
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file] = true
+	}
+
+	for _, file := range files {
+		switch {
+		case strings.HasSuffix(file, "_templ.go"):
+			if source := strings.TrimSuffix(file, "_templ.go") + ".templ"; !present[source] {
+				issues = append(issues, LintIssue{Check: "templ", Path: file, Warning: true,
+					Message: fmt.Sprintf("generated file has no %s source", path.Base(source))})
+			}
+
+			content, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template file %s: %w", file, err)
+			}
+
+			m := templVersionRe.FindSubmatch(content)
+			switch {
+			case m == nil:
+				issues = append(issues, LintIssue{Check: "templ", Path: file,
+					Message: "missing '// templ: version:' header - regenerate it with templ generate"})
+			case required != "" && string(m[1]) != required:
+				issues = append(issues, LintIssue{Check: "templ", Path: file,
+					Message: fmt.Sprintf("generated with templ %s but go.mod requires %s - regenerate it with templ generate", m[1], required)})
+			}
+		case strings.HasSuffix(file, ".templ"):
+			if generated := strings.TrimSuffix(file, ".templ") + "_templ.go"; !present[generated] {
+				issues = append(issues, LintIssue{Check: "templ", Path: file, Warning: true,
+					Message: fmt.Sprintf("%s has not been generated", path.Base(generated))})
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// requiredVersion returns the version of modulePath required by the template's go.mod, if any
+func requiredVersion(fsys fs.FS, modulePath string) string {
+	for _, name := range []string{"go.mod.template", "go.mod"} {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+
+		file, err := modfile.ParseLax(name, content, nil)
+		if err != nil {
+			return ""
+		}
+		for _, req := range file.Require {
+			if req.Mod.Path == modulePath {
+				return req.Mod.Version
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// lintTypes renders the template with sample variables and every feature
+// enabled, then type-checks the generated Go packages
+func lintTypes(tmpl *Template, manifest *Manifest) []LintIssue {
+	values := map[string]string{}
+	for _, v := range manifest.Variables {
+		if v.Default != nil {
+			continue
+		}
+
+		sample := sampleValue(v)
+		if _, err := v.Parse(sample); err != nil {
+			return []LintIssue{{Check: "types", Path: ManifestFile,
+				Message: fmt.Sprintf("cannot render with sample values: variable '%s' has no default and '%s' does not fit it", v.Name, sample)}}
+		}
+		values[v.Name] = sample
+	}
+
+	features := map[string]bool{}
+	for _, f := range manifest.Features {
+		features[f.Name] = true
+	}
+
+	project, err := Render(tmpl, "sample-project", Options{Values: values, Features: features})
+	if err != nil {
+		return []LintIssue{templateIssue("types", ManifestFile, err)}
+	}
+
+	return typeCheck(project)
+}
+
+// sampleValue picks a value for a variable without a default
+func sampleValue(v Variable) string {
+	if len(v.Choices) > 0 {
+		return v.Choices[0]
+	}
+
+	switch v.Type {
+	case TypeBool:
+		return "true"
+	case TypeInt:
+		return "1"
+	}
+	return "example"
+}
+
+// errNotChecked is returned for imports that are neither in the project nor in the standard library
+var errNotChecked = errors.New("third-party package not checked")
+
+// projectImporter type-checks the packages of a rendered project on demand,
+// importing the standard library and leaving third-party packages unresolved
+type projectImporter struct {
+	fset     *token.FileSet
+	std      types.Importer
+	packages map[string][]*ast.File
+	checked  map[string]*types.Package
+	issues   []LintIssue
+}
+
+func (imp *projectImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := imp.checked[importPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through %s", importPath)
+		}
+		return pkg, nil
+	}
+
+	if files, ok := imp.packages[importPath]; ok {
+		return imp.check(importPath, files), nil
+	}
+
+	if isStdlib(importPath) {
+		return imp.std.Import(importPath)
+	}
+
+	return nil, errNotChecked
+}
+
+// isStdlib reports whether importPath belongs to the standard library, whose
+// paths have no dot in their first element
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// assumedPackageName guesses the name of a package from its import path the
+// way goimports does, skipping major version suffixes and go- prefixes
+func assumedPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersionRe.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".go"), "-go")
+	return strings.NewReplacer("-", "", ".", "").Replace(name)
+}
+
+// check type-checks a single project package, recording its errors as issues
+func (imp *projectImporter) check(importPath string, files []*ast.File) *types.Package {
+	imp.checked[importPath] = nil
+
+	conf := types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			var typeErr types.Error
+			if !errors.As(err, &typeErr) {
+				return
+			}
+			// Uses of unresolved packages are not reported by go/types, only the import itself
+			if strings.Contains(typeErr.Msg, errNotChecked.Error()) {
+				return
+			}
+
+			pos := typeErr.Fset.Position(typeErr.Pos)
+			imp.issues = append(imp.issues, LintIssue{Check: "types", Path: pos.Filename, Line: pos.Line,
+				Message: typeErr.Msg + " (in the rendered file)"})
+		},
+	}
+
+	pkg, _ := conf.Check(importPath, imp.fset, files, nil)
+	imp.checked[importPath] = pkg
+	return pkg
+}
+
+// typeCheck parses and type-checks every Go package of a rendered project
+func typeCheck(project *Project) []LintIssue {
+	imp := &projectImporter{
+		fset:     token.NewFileSet(),
+		packages: map[string][]*ast.File{},
+		checked:  map[string]*types.Package{},
+	}
+	imp.std = importer.ForCompiler(imp.fset, "source", nil)
+
+	for _, file := range project.Files {
+		if path.Ext(file.Path) != ".go" || strings.HasSuffix(file.Path, "_test.go") {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(imp.fset, file.Path, file.Content, parser.SkipObjectResolution)
+		if err != nil {
+			var list scanner.ErrorList
+			if errors.As(err, &list) && len(list) > 0 {
+				for _, e := range list {
+					imp.issues = append(imp.issues, LintIssue{Check: "types", Path: e.Pos.Filename, Line: e.Pos.Line,
+						Message: e.Msg + " (in the rendered file)"})
+				}
+				continue
+			}
+			imp.issues = append(imp.issues, LintIssue{Check: "types", Path: file.Path, Message: err.Error()})
+			continue
+		}
+
+		importPath := project.Data.ModulePath
+		if dir := path.Dir(file.Path); dir != "." {
+			importPath += "/" + dir
+		}
+		imp.packages[importPath] = append(imp.packages[importPath], parsed)
+	}
+
+	importPaths := make([]string, 0, len(imp.packages))
+	for importPath, files := range imp.packages {
+		importPaths = append(importPaths, importPath)
+
+		// go/types names unresolved packages after the last path element, which
+		// is wrong for paths like .../pgx/v5, so name them explicitly
+		for _, file := range files {
+			for _, spec := range file.Imports {
+				specPath, _ := strconv.Unquote(spec.Path.Value)
+				if _, local := imp.packages[specPath]; spec.Name == nil && !local && !isStdlib(specPath) {
+					spec.Name = ast.NewIdent(assumedPackageName(specPath))
+				}
+			}
+		}
+	}
+	sort.Strings(importPaths)
+
+	for _, importPath := range importPaths {
+		if _, ok := imp.checked[importPath]; !ok {
+			imp.check(importPath, imp.packages[importPath])
+		}
+	}
+
+	return imp.issues
+}
@@ -0,0 +1,144 @@
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ironlabsdev/iron/internal/version"
+)
+
+// LockfilePath is where the lockfile is written inside generated projects
+const LockfilePath = ".iron/project.json"
+
+// Lockfile records how a project was generated
+type Lockfile struct {
+	Template         string            `json:"template"`
+	TemplateVersion  string            `json:"templateVersion,omitempty"`
+	TemplateSource   string            `json:"templateSource"`
+	TemplateChecksum string            `json:"templateChecksum"`
+	Iron             version.BuildInfo `json:"iron"`
+	GeneratedAt      time.Time         `json:"generatedAt"`
+	ProjectName      string            `json:"projectName"`
+	ModulePath       string            `json:"modulePath"`
+	Variables        map[string]any    `json:"variables"`
+	Features         map[string]bool   `json:"features"`
+	// Files maps every generated file to the SHA-256 of its rendered content
+	Files map[string]string `json:"files"`
+	// HookFiles maps the files created or rewritten by the post-generation
+	// hooks, such as go.sum, to the SHA-256 of their content once the hooks ran
+	HookFiles map[string]string `json:"hookFiles,omitempty"`
+}
+
+// hookOwned reports whether content is what the post-generation hooks left
+// in the file, so it was not edited since
+func (l *Lockfile) hookOwned(relPath string, content []byte) bool {
+	sum, ok := l.HookFiles[relPath]
+	return ok && sum == checksum(content)
+}
+
+// keepHookFiles records the hook outputs of a previous lockfile that the
+// project at fullPath still holds unchanged
+func (l *Lockfile) keepHookFiles(fullPath string, previous map[string]string) error {
+	current, err := projectChecksums(fullPath)
+	if err != nil {
+		return err
+	}
+
+	for relPath, sum := range previous {
+		if current[relPath] != sum {
+			continue
+		}
+		if l.HookFiles == nil {
+			l.HookFiles = map[string]string{}
+		}
+		l.HookFiles[relPath] = sum
+	}
+	return nil
+}
+
+// newLockfile describes a rendered project
+func newLockfile(project *Project) (*Lockfile, error) {
+	files := make(map[string]string, len(project.Files))
+	for _, file := range project.Files {
+		sum, err := file.checksum()
+		if err != nil {
+			return nil, err
+		}
+		files[file.Path] = sum
+	}
+
+	return &Lockfile{
+		Template:         project.Template,
+		TemplateVersion:  project.Manifest.Version,
+		TemplateSource:   project.Source,
+		TemplateChecksum: project.TemplateChecksum,
+		Iron:             version.GetBuildInfo(),
+		GeneratedAt:      time.Now().UTC().Truncate(time.Second),
+		ProjectName:      project.Data.ProjectName,
+		ModulePath:       project.Data.ModulePath,
+		Variables:        project.Data.Vars,
+		Features:         project.Data.Features,
+		Files:            files,
+	}, nil
+}
+
+// writeLockfile writes the lockfile into the project directory
+func writeLockfile(fullPath string, lock *Lockfile) error {
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", LockfilePath, err)
+	}
+
+	return writeFile(filepath.Join(fullPath, filepath.FromSlash(LockfilePath)), append(content, '\n'))
+}
+
+// ReadLockfile reads the lockfile of the project in dir
+func ReadLockfile(dir string) (*Lockfile, error) {
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(LockfilePath)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("'%s' was not generated by iron (no %s found)", dir, LockfilePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", LockfilePath, err)
+	}
+
+	var lock Lockfile
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", LockfilePath, err)
+	}
+
+	return &lock, nil
+}
+
+// templateChecksum hashes every file of a template that is not ignored, names included, in walk order
+func templateChecksum(fsys fs.FS) (string, error) {
+	h := sha256.New()
+
+	err := walkTemplateFiles(fsys, func(filePath string, d fs.DirEntry) error {
+		content, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", filePath, checksum(content))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum template: %w", err)
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksum returns the SHA-256 of content
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,296 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the template manifest at the root of every template
+const ManifestFile = "iron.yaml"
+
+// Variable types
+const (
+	TypeString = "string"
+	TypeBool   = "bool"
+	TypeInt    = "int"
+)
+
+// variableNameRe restricts variable names to identifiers usable as .Vars.name
+var variableNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Manifest describes a template and the variables it accepts
+type Manifest struct {
+	Name        string     `yaml:"name"`
+	Version     string     `yaml:"version"`
+	Description string     `yaml:"description"`
+	Variables   []Variable `yaml:"variables"`
+	Features    []Feature  `yaml:"features"`
+	Hooks       []Hook     `yaml:"hooks"`
+	// Delimiters replace {{ and }} in every template file, e.g. ["[[", "]]"]
+	Delimiters []string `yaml:"delimiters"`
+	// Files change the delimiters of, or copy verbatim, the files matching their paths
+	Files []FileRule `yaml:"files"`
+	// Executable are globs of files generated with the executable bit set
+	Executable []string `yaml:"executable"`
+	// CreateOnly are files generated only when they do not exist yet
+	CreateOnly []CreateOnlyFile `yaml:"create_only"`
+}
+
+// Variable is a value supplied when generating a template, available as .Vars.<name>
+type Variable struct {
+	Name string `yaml:"name"`
+	// Type is string, bool or int. Defaults to string.
+	Type string `yaml:"type"`
+	// Default may reference the project name values, e.g. {{.ProjectNameSnake}}.
+	// Variables without a default are required.
+	Default *string  `yaml:"default"`
+	Pattern string   `yaml:"pattern"`
+	Choices []string `yaml:"choices"`
+	Help    string   `yaml:"help"`
+}
+
+// LoadManifest reads and validates the manifest of a template.
+// Templates without a manifest get an empty one named after the template.
+func LoadManifest(tmpl *Template) (*Manifest, error) {
+	content, err := fs.ReadFile(tmpl.FS, ManifestFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Manifest{Name: tmpl.Name}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s of template '%s': %w", ManifestFile, tmpl.Name, err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s in template '%s': %w", ManifestFile, tmpl.Name, err)
+	}
+
+	if manifest.Name == "" {
+		manifest.Name = tmpl.Name
+	}
+
+	if err := manifest.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s in template '%s': %w", ManifestFile, tmpl.Name, err)
+	}
+
+	return &manifest, nil
+}
+
+// validate checks the variable, feature, delimiter, create_only and hook declarations of the manifest
+func (m *Manifest) validate() error {
+	seen := map[string]bool{}
+
+	for i := range m.Variables {
+		v := &m.Variables[i]
+
+		if !variableNameRe.MatchString(v.Name) {
+			return fmt.Errorf("variable name '%s' must be a letter or underscore followed by letters, digits or underscores", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("variable '%s' is declared more than once", v.Name)
+		}
+		seen[v.Name] = true
+
+		if v.Type == "" {
+			v.Type = TypeString
+		}
+
+		switch v.Type {
+		case TypeString, TypeBool, TypeInt:
+		default:
+			return fmt.Errorf("variable '%s' has unknown type '%s' - use %s, %s or %s", v.Name, v.Type, TypeString, TypeBool, TypeInt)
+		}
+
+		if v.Pattern != "" {
+			if _, err := regexp.Compile(v.Pattern); err != nil {
+				return fmt.Errorf("variable '%s' has an invalid pattern: %w", v.Name, err)
+			}
+		}
+	}
+
+	if err := m.validateFeatures(); err != nil {
+		return err
+	}
+
+	if err := m.validateDelimiters(); err != nil {
+		return err
+	}
+
+	if err := m.validateCreateOnly(); err != nil {
+		return err
+	}
+
+	return m.validateHooks()
+}
+
+// Variable returns the declared variable called name
+func (m *Manifest) Variable(name string) (Variable, bool) {
+	for _, v := range m.Variables {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variable{}, false
+}
+
+// ResolveVars validates the supplied values against the declared variables and
+// fills in the rest. Missing values are asked for with the prompter when one is
+// given, otherwise defaults are used, rendered with the project name values in data.
+func (m *Manifest) ResolveVars(values map[string]string, data templateData, prompter Prompter) (map[string]any, error) {
+	var problems []string
+
+	var unknown []string
+	for name := range values {
+		if _, ok := m.Variable(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown variable '%s'", name))
+	}
+
+	// Validate everything supplied up front so we fail before prompting
+	vars := make(map[string]any, len(m.Variables))
+	for _, v := range m.Variables {
+		raw, ok := values[v.Name]
+		if !ok {
+			continue
+		}
+
+		value, err := v.Parse(raw)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		vars[v.Name] = value
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid template variables:\n  %s%s", strings.Join(problems, "\n  "), m.variablesHelp())
+	}
+
+	var missing []string
+	for _, v := range m.Variables {
+		if _, ok := values[v.Name]; ok {
+			continue
+		}
+
+		defaultValue, hasDefault := "", v.Default != nil
+		if hasDefault {
+			rendered, err := renderDefault(*v.Default, data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default for variable '%s': %w", v.Name, err)
+			}
+			defaultValue = rendered
+		}
+
+		raw := defaultValue
+		switch {
+		case prompter != nil:
+			answer, err := prompter.Prompt(v, defaultValue, hasDefault)
+			if err != nil {
+				return nil, err
+			}
+			raw = answer
+		case !hasDefault:
+			missing = append(missing, v.Name)
+			continue
+		}
+
+		value, err := v.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default for variable '%s': %w", v.Name, err)
+		}
+		vars[v.Name] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing values for required variables: %s\nPass them with --set name=value, or run iron on a terminal without --no-input to be prompted%s",
+			strings.Join(missing, ", "), m.variablesHelp())
+	}
+
+	return vars, nil
+}
+
+// Parse converts a raw value to the variable type and validates it
+func (v Variable) Parse(raw string) (any, error) {
+	if len(v.Choices) > 0 && !slices.Contains(v.Choices, raw) {
+		return nil, fmt.Errorf("invalid value '%s' for variable '%s': must be one of %s", raw, v.Name, strings.Join(v.Choices, ", "))
+	}
+
+	if v.Pattern != "" && !regexp.MustCompile(v.Pattern).MatchString(raw) {
+		return nil, fmt.Errorf("invalid value '%s' for variable '%s': must match %s", raw, v.Name, v.Pattern)
+	}
+
+	switch v.Type {
+	case TypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for variable '%s': must be true or false", raw, v.Name)
+		}
+		return b, nil
+	case TypeInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for variable '%s': must be an integer", raw, v.Name)
+		}
+		return n, nil
+	}
+
+	return raw, nil
+}
+
+// variablesHelp lists the declared variables for error messages
+func (m *Manifest) variablesHelp() string {
+	if len(m.Variables) == 0 {
+		return fmt.Sprintf("\nTemplate '%s' does not declare any variables", m.Name)
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "\nVariables of template '%s' (set with --set name=value):", m.Name)
+	for _, v := range m.Variables {
+		_, _ = fmt.Fprintf(&b, "\n  %-18s %s", v.Name, v.Help)
+	}
+	return b.String()
+}
+
+// renderDefault renders a variable default as a template
+func renderDefault(value string, data templateData) (string, error) {
+	tmpl, err := template.New("default").Option("missingkey=error").Funcs(templateFuncs).Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// parseSetFlags parses key=value pairs given with --set
+func parseSetFlags(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set value '%s' - use --set name=value", pair)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
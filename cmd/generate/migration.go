@@ -0,0 +1,383 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// migrationsDir is where projects generated from the oauth template keep their golang-migrate files
+const migrationsDir = "cmd/migrate/migrations"
+
+// Version schemes of golang-migrate files
+const (
+	// VersionSequential numbers migrations 000001, 000002...
+	VersionSequential = "sequential"
+	// VersionTimestamp numbers migrations with their creation time, e.g. 20250102150405
+	VersionTimestamp = "timestamp"
+
+	timestampLayout = "20060102150405"
+)
+
+var (
+	migrationCreateTable string
+	migrationAddColumns  []string
+	migrationDir         string
+	migrationProjectDir  string
+	migrationDryRun      bool
+)
+
+// MigrationCmd adds a golang-migrate migration to an existing project
+var MigrationCmd = &cobra.Command{
+	Use:   "migration <name>",
+	Short: "Add the next up and down migration files to an existing project",
+	Long: `Create the next <version>_<name>.up.sql and .down.sql pair in
+cmd/migrate/migrations of a project generated from the oauth template.
+
+The version follows the numbering of the existing migrations, either
+sequential (000001, 000002...) or timestamps (20250102150405). Gaps, duplicate
+versions and migrations missing their up or down file are reported.
+
+The files contain a comment unless the SQL is described with flags:
+  --create-table posts               CREATE TABLE with id and timestamps
+  --add-column users.bio:text        ALTER TABLE ... ADD COLUMN
+  --add-column title:text            a column of the table being created
+
+Example:
+  iron generate migration add_posts --create-table posts --add-column title:text`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return AddMigration(MigrationOptions{
+			Name:          args[0],
+			CreateTable:   migrationCreateTable,
+			AddColumns:    migrationAddColumns,
+			MigrationsDir: migrationDir,
+			ProjectDir:    migrationProjectDir,
+			DryRun:        migrationDryRun,
+		})
+	},
+}
+
+func init() {
+	MigrationCmd.Flags().StringVar(&migrationCreateTable, "create-table", "", "Create a table with this name")
+	MigrationCmd.Flags().StringArrayVar(&migrationAddColumns, "add-column", nil, "Add a column as [table.]column[:type], the type defaults to text (repeatable)")
+	MigrationCmd.Flags().StringVar(&migrationDir, "migrations-dir", migrationsDir, "Directory of the migrations, relative to the project")
+	MigrationCmd.Flags().StringVarP(&migrationProjectDir, "dir", "C", ".", "Directory of the project")
+	MigrationCmd.Flags().BoolVar(&migrationDryRun, "dry-run", false, "Show the files that would be created without writing them")
+	GenerateCmd.AddCommand(MigrationCmd)
+}
+
+// MigrationOptions configures AddMigration
+type MigrationOptions struct {
+	// Name of the migration, e.g. add_posts
+	Name        string
+	CreateTable string
+	// AddColumns are [table.]column[:type] specs
+	AddColumns    []string
+	MigrationsDir string
+	ProjectDir    string
+	DryRun        bool
+}
+
+// migrationData is the data of the migration component skeletons
+type migrationData struct {
+	Name        string
+	CreateTable *migrationTable
+	AddColumns  []migrationColumn
+}
+
+// migrationTable is a table created by a migration
+type migrationTable struct {
+	Name    string
+	Columns []migrationColumn
+	// Width aligns the column types
+	Width int
+}
+
+// migrationColumn is a column of a created table, or a column added to an existing one
+type migrationColumn struct {
+	Table string
+	Name  string
+	// Type is the SQL type with its constraints, e.g. TEXT NOT NULL
+	Type string
+}
+
+// DropColumns lists the added columns in the order the down migration drops them
+func (d migrationData) DropColumns() []migrationColumn {
+	columns := slices.Clone(d.AddColumns)
+	slices.Reverse(columns)
+	return columns
+}
+
+var (
+	// migrationFileRe matches golang-migrate files: <version>_<name>.<up|down>.sql
+	migrationFileRe = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+	sqlIdentifierRe = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	sqlTypeRe       = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\([0-9]+(, *[0-9]+)?\))?(\[\])?$`)
+)
+
+// migration is an existing up and down migration pair
+type migration struct {
+	Version string
+	Name    string
+	Up      bool
+	Down    bool
+}
+
+// AddMigration creates the next migration of an existing project
+func AddMigration(opts MigrationOptions) error {
+	project, err := findProject(opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	if opts.MigrationsDir == "" {
+		opts.MigrationsDir = migrationsDir
+	}
+	if err := project.require(opts.MigrationsDir); err != nil {
+		return err
+	}
+
+	name := utils.ToSnakeCase(opts.Name)
+	if !sqlIdentifierRe.MatchString(name) {
+		return fmt.Errorf("invalid migration name '%s' - use letters, digits and underscores, starting with a letter", opts.Name)
+	}
+
+	data, err := newMigrationData(name, opts.CreateTable, opts.AddColumns)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := readMigrations(project, opts.MigrationsDir)
+	if err != nil {
+		return err
+	}
+	scheme, version := nextMigrationVersion(migrations, time.Now().UTC())
+
+	changes, err := project.migrationChanges(opts.MigrationsDir, version+"_"+name, data)
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		fmt.Printf("%sDry run: migration %s_%s (%s versions, no files written)%s\n\n", ColorBlue, version, name, scheme, ColorReset)
+		printComponentChanges(os.Stdout, changes, true)
+	} else {
+		if err := project.applyComponentChanges(changes); err != nil {
+			return fmt.Errorf("failed to add migration: %w", err)
+		}
+		printComponentChanges(os.Stdout, changes, false)
+		fmt.Printf("%s✓ Added migration %s_%s%s\n", ColorGreen, version, name, ColorReset)
+	}
+
+	for _, warning := range migrationWarnings(migrations) {
+		fmt.Printf("%s! %s%s\n", ColorYellow, warning, ColorReset)
+	}
+	return nil
+}
+
+// newMigrationData validates the table and column flags
+func newMigrationData(name, createTable string, addColumns []string) (migrationData, error) {
+	data := migrationData{Name: name}
+
+	if createTable != "" {
+		if !sqlIdentifierRe.MatchString(createTable) {
+			return data, fmt.Errorf("invalid table name '%s' - use lower-case letters, digits and underscores", createTable)
+		}
+		data.CreateTable = &migrationTable{Name: createTable}
+	}
+
+	for _, spec := range addColumns {
+		column, err := parseColumnSpec(spec)
+		if err != nil {
+			return data, err
+		}
+
+		switch {
+		case column.Table == "" && data.CreateTable == nil:
+			return data, fmt.Errorf("column '%s' needs a table - use table.column or --create-table", spec)
+		case column.Table == "" || (data.CreateTable != nil && column.Table == data.CreateTable.Name):
+			data.CreateTable.Columns = append(data.CreateTable.Columns, column)
+		default:
+			data.AddColumns = append(data.AddColumns, column)
+		}
+	}
+
+	if data.CreateTable != nil {
+		data.CreateTable.Width = len("created_at")
+		for _, column := range data.CreateTable.Columns {
+			data.CreateTable.Width = max(data.CreateTable.Width, len(column.Name))
+		}
+	}
+
+	return data, nil
+}
+
+// parseColumnSpec parses [table.]column[:type]
+func parseColumnSpec(spec string) (migrationColumn, error) {
+	nameSpec, sqlType, _ := strings.Cut(spec, ":")
+	table, name, ok := strings.Cut(nameSpec, ".")
+	if !ok {
+		table, name = "", nameSpec
+	}
+
+	if sqlType == "" {
+		sqlType = "text"
+	}
+	if !sqlTypeRe.MatchString(sqlType) {
+		return migrationColumn{}, fmt.Errorf("invalid column type '%s' in '%s'", sqlType, spec)
+	}
+	if !sqlIdentifierRe.MatchString(name) || (ok && !sqlIdentifierRe.MatchString(table)) {
+		return migrationColumn{}, fmt.Errorf("invalid column '%s' - use [table.]column[:type] with lower-case names", spec)
+	}
+
+	return migrationColumn{Table: table, Name: name, Type: strings.ToUpper(sqlType)}, nil
+}
+
+// readMigrations lists the migrations of the project, sorted by version
+func readMigrations(project *existingProject, dir string) ([]migration, error) {
+	entries, err := os.ReadDir(project.path(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	byFile := map[string]*migration{}
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		key := match[1] + "_" + match[2]
+		m, ok := byFile[key]
+		if !ok {
+			m = &migration{Version: match[1], Name: match[2]}
+			byFile[key] = m
+		}
+		if match[3] == "up" {
+			m.Up = true
+		} else {
+			m.Down = true
+		}
+	}
+
+	migrations := make([]migration, 0, len(byFile))
+	for _, m := range byFile {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		vi, vj := versionNumber(migrations[i].Version), versionNumber(migrations[j].Version)
+		if vi != vj {
+			return vi < vj
+		}
+		return migrations[i].Name < migrations[j].Name
+	})
+	return migrations, nil
+}
+
+// versionNumber parses a migration version, which the file pattern guarantees to be digits
+func versionNumber(version string) uint64 {
+	n, _ := strconv.ParseUint(version, 10, 64)
+	return n
+}
+
+// versionScheme tells timestamp versions from sequential ones
+func versionScheme(version string) string {
+	if _, err := time.Parse(timestampLayout, version); err == nil && len(version) == len(timestampLayout) {
+		return VersionTimestamp
+	}
+	return VersionSequential
+}
+
+// nextMigrationVersion follows the scheme of the latest migration. Projects
+// without migrations get sequential versions of six digits.
+func nextMigrationVersion(migrations []migration, now time.Time) (string, string) {
+	if len(migrations) == 0 {
+		return VersionSequential, "000001"
+	}
+
+	latest := migrations[len(migrations)-1].Version
+	if versionScheme(latest) == VersionTimestamp {
+		next := now.Format(timestampLayout)
+		if versionNumber(next) <= versionNumber(latest) {
+			next = strconv.FormatUint(versionNumber(latest)+1, 10)
+		}
+		return VersionTimestamp, next
+	}
+
+	return VersionSequential, fmt.Sprintf("%0*d", len(latest), versionNumber(latest)+1)
+}
+
+// migrationWarnings reports duplicate versions, gaps between sequential
+// versions, mixed schemes and migrations missing their up or down file
+func migrationWarnings(migrations []migration) []string {
+	var warnings []string
+
+	byVersion := map[uint64][]string{}
+	schemes := map[string]bool{}
+	var sequential []uint64
+	for _, m := range migrations {
+		n := versionNumber(m.Version)
+		if len(byVersion[n]) == 0 && versionScheme(m.Version) == VersionSequential {
+			sequential = append(sequential, n)
+		}
+		byVersion[n] = append(byVersion[n], m.Version+"_"+m.Name)
+		schemes[versionScheme(m.Version)] = true
+
+		switch {
+		case !m.Up:
+			warnings = append(warnings, fmt.Sprintf("migration %s_%s has no .up.sql file", m.Version, m.Name))
+		case !m.Down:
+			warnings = append(warnings, fmt.Sprintf("migration %s_%s has no .down.sql file", m.Version, m.Name))
+		}
+	}
+
+	for _, m := range migrations {
+		if names := byVersion[versionNumber(m.Version)]; len(names) > 1 && names[0] == m.Version+"_"+m.Name {
+			warnings = append(warnings, fmt.Sprintf("version %s is used by several migrations (%s) - golang-migrate will refuse to run them, renumber all but one",
+				m.Version, strings.Join(names, ", ")))
+		}
+	}
+
+	for i := 1; i < len(sequential); i++ {
+		switch from, to := sequential[i-1]+1, sequential[i]-1; {
+		case from == to:
+			warnings = append(warnings, fmt.Sprintf("version %d is missing between migrations %d and %d", from, from-1, to+1))
+		case from < to:
+			warnings = append(warnings, fmt.Sprintf("versions %d to %d are missing between migrations %d and %d", from, to, from-1, to+1))
+		}
+	}
+
+	if len(schemes) > 1 {
+		warnings = append(warnings, "migrations mix sequential and timestamp versions - they run in numeric order, so the sequential ones always run first")
+	}
+
+	return warnings
+}
+
+// migrationChanges renders the up and down files of a migration called base, e.g. 000003_add_posts
+func (p *existingProject) migrationChanges(dir, base string, data migrationData) ([]componentChange, error) {
+	var changes []componentChange
+	for _, direction := range []string{"up", "down"} {
+		content, err := renderComponent(path.Join("migration", direction+".sql.tmpl"), data)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(strings.TrimSpace(string(content)) + "\n")
+
+		change, err := p.newComponentChange(path.Join(dir, base+"."+direction+".sql"), content, false)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
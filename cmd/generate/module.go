@@ -0,0 +1,106 @@
+package generate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+var (
+	// moduleLineRe matches the module directive of a go.mod file
+	moduleLineRe = regexp.MustCompile(`(?m)^module\s+\S+`)
+	// importLineRe matches a single import spec, with or without the import keyword and alias
+	importLineRe = regexp.MustCompile(`^(\s*(?:import\s+)?(?:[\w.]+\s+)?)"([^"]+)"(.*)$`)
+)
+
+// templateModulePath reads the module path declared by the template's go.mod
+func templateModulePath(fsys fs.FS) (string, error) {
+	for _, name := range []string{"go.mod.template", "go.mod"} {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+
+		modulePath := modfile.ModulePath(content)
+		if modulePath == "" {
+			return "", fmt.Errorf("template file %s does not declare a module path", name)
+		}
+		return modulePath, nil
+	}
+
+	// Templates without a go.mod have no module path to rewrite
+	return "", nil
+}
+
+// defaultModulePath derives a module path from the project name
+func defaultModulePath(projectName string) string {
+	return utils.ToKebabCase(projectName)
+}
+
+// validateModulePath checks that the module path can be used in a go.mod file
+func validateModulePath(modulePath string) error {
+	if err := module.CheckImportPath(modulePath); err != nil {
+		return fmt.Errorf("invalid module path '%s': %w", modulePath, err)
+	}
+	return nil
+}
+
+// rewriteModulePath replaces the template module path with the project module path
+// in go.mod files and in the import declarations of Go and templ sources
+func rewriteModulePath(content []byte, filePath, from, to string) []byte {
+	if from == "" || from == to {
+		return content
+	}
+
+	name := path.Base(filePath)
+	switch {
+	case name == "go.mod" || name == "go.mod.template":
+		return moduleLineRe.ReplaceAll(content, []byte("module "+to))
+	case strings.HasSuffix(name, ".go") || strings.HasSuffix(name, ".templ"):
+		return rewriteImports(content, from, to)
+	}
+
+	return content
+}
+
+// rewriteImports rewrites import paths rooted at the from module path.
+// Only import declarations are touched, so string literals in code are left alone.
+func rewriteImports(content []byte, from, to string) []byte {
+	lines := strings.Split(string(content), "\n")
+	inBlock := false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "import ("):
+			inBlock = true
+			continue
+		case inBlock && strings.HasPrefix(trimmed, ")"):
+			inBlock = false
+			continue
+		case !inBlock && !strings.HasPrefix(trimmed, "import "):
+			continue
+		}
+
+		match := importLineRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		importPath := match[2]
+		if importPath != from && !strings.HasPrefix(importPath, from+"/") {
+			continue
+		}
+
+		lines[i] = match[1] + `"` + to + strings.TrimPrefix(importPath, from) + `"` + match[3]
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,168 @@
+package generate
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ironlabsdev/iron/internal/utils"
+	"golang.org/x/mod/module"
+)
+
+// maxPostgresIdentifier is the length Postgres truncates identifiers to
+const maxPostgresIdentifier = 63
+
+var (
+	// projectNameRe matches the characters the case conversions keep as they are
+	projectNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+	// composeProjectRe matches valid Docker Compose project names
+	composeProjectRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+// postgresReserved are the keywords Postgres does not accept as unquoted
+// database, table or column names
+var postgresReserved = []string{
+	"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric", "both",
+	"case", "cast", "check", "collate", "column", "constraint", "create", "current_catalog",
+	"current_date", "current_role", "current_time", "current_timestamp", "current_user",
+	"default", "deferrable", "desc", "distinct", "do", "else", "end", "except", "false",
+	"fetch", "for", "foreign", "from", "grant", "group", "having", "in", "initially",
+	"intersect", "into", "lateral", "leading", "limit", "localtime", "localtimestamp", "not",
+	"null", "offset", "on", "only", "or", "order", "placing", "primary", "references",
+	"returning", "select", "session_user", "some", "symmetric", "system_user", "table",
+	"then", "to", "trailing", "true", "union", "unique", "user", "using", "variadic", "when",
+	"where", "window", "with",
+}
+
+// transliterations spell common accented Latin letters in ASCII, by their lower case
+var transliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ß': "ss", 'ť': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// validateProjectName checks that the values derived from the project name are
+// valid where templates use them: Go identifiers, the default module path,
+// Postgres identifiers and Docker Compose project names. The error suggests a
+// name that is valid when there is one.
+func validateProjectName(name string, checkModule bool) error {
+	problems := projectNameProblems(name, checkModule)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	hint := "Choose another name with --name"
+	if suggestion := suggestProjectName(name); suggestion != "" {
+		hint = fmt.Sprintf("Choose another name with --name, e.g. --name %s", suggestion)
+	}
+	return fmt.Errorf("invalid project name '%s':\n  %s\n%s", name, strings.Join(problems, "\n  "), hint)
+}
+
+// projectNameProblems lists the naming rules broken by name or the values derived from it
+func projectNameProblems(name string, checkModule bool) []string {
+	var problems []string
+
+	switch {
+	case name == "":
+		return []string{"it is empty"}
+	case !projectNameRe.MatchString(name):
+		problems = append(problems, "it may only contain ASCII letters, digits, dashes and underscores")
+	case !isASCIILetter(name[0]):
+		problems = append(problems, "it must start with a letter")
+	}
+
+	camel := utils.ToCamelCase(name)
+	switch {
+	case token.IsKeyword(camel):
+		problems = append(problems, fmt.Sprintf("%s is a Go keyword", camel))
+	case !token.IsIdentifier(camel):
+		problems = append(problems, fmt.Sprintf("%s is not a valid Go identifier", camel))
+	}
+
+	if checkModule {
+		if err := module.CheckImportPath(defaultModulePath(name)); err != nil {
+			problems = append(problems, fmt.Sprintf("%v - set the module path with --module", err))
+		}
+	}
+
+	snake := utils.ToSnakeCase(name)
+	switch {
+	case !sqlIdentifierRe.MatchString(snake):
+		problems = append(problems, fmt.Sprintf("%s is not a valid Postgres identifier", snake))
+	case len(snake) > maxPostgresIdentifier:
+		problems = append(problems, fmt.Sprintf("%s is longer than the %d bytes of a Postgres identifier", snake, maxPostgresIdentifier))
+	case slices.Contains(postgresReserved, snake):
+		problems = append(problems, fmt.Sprintf("%s is a reserved Postgres keyword", snake))
+	}
+
+	if kebab := utils.ToKebabCase(name); !composeProjectRe.MatchString(kebab) {
+		problems = append(problems, fmt.Sprintf("%s is not a valid Docker Compose project name", kebab))
+	}
+
+	return problems
+}
+
+// suggestProjectName sanitises name into a valid kebab case project name, or
+// returns "" when nothing usable is left. Accented letters are spelled in
+// ASCII, other characters separate words, leading numbers move to the end and
+// keywords get an -app suffix, so my.app gives my-app, Ünïcode gives unicode,
+// 123-app gives app-123 and type gives type-app. Names that would lose most of
+// their letters get no suggestion.
+func suggestProjectName(name string) string {
+	var ascii strings.Builder
+	letters, dropped := 0, 0
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letters++
+		}
+		switch spelled, ok := transliterations[unicode.ToLower(r)]; {
+		case r < 128 && (isASCIILetter(byte(r)) || '0' <= r && r <= '9'):
+			ascii.WriteRune(r)
+		case ok:
+			ascii.WriteString(spelled)
+		default:
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				dropped++
+			}
+			ascii.WriteRune(' ')
+		}
+	}
+	if dropped*2 > letters {
+		return ""
+	}
+	suggestion := utils.ToKebabCase(ascii.String())
+
+	start := strings.IndexFunc(suggestion, func(r rune) bool { return isASCIILetter(byte(r)) })
+	if start < 0 {
+		return ""
+	}
+	if prefix := strings.Trim(suggestion[:start], "-"); prefix != "" {
+		suggestion = suggestion[start:] + "-" + prefix
+	}
+
+	snake := utils.ToSnakeCase(suggestion)
+	if token.IsKeyword(utils.ToCamelCase(suggestion)) || slices.Contains(postgresReserved, snake) {
+		suggestion += "-app"
+	}
+	if len(suggestion) > maxPostgresIdentifier {
+		suggestion = strings.TrimRight(suggestion[:maxPostgresIdentifier], "-")
+	}
+
+	if len(projectNameProblems(suggestion, true)) > 0 {
+		return ""
+	}
+	return suggestion
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,47 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggestProjectName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"my.app", "my-app"},
+		{"My App", "my-app"},
+		{"123-app", "app-123"},
+		{"type", "type-app"},
+		{"select", "select-app"},
+		{"Ünïcode", "unicode"},
+		{"café-bar", "cafe-bar"},
+		{"Straße", "strasse"},
+		{"smørrebrød", "smorrebrod"},
+		{"crème brûlée", "creme-brulee"},
+		// Names losing most of their letters get no suggestion
+		{"日本語アプリ", ""},
+		{"日本語アプリ-app", ""},
+		{"my-アプリ-app", "my-app"},
+		{"123", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := suggestProjectName(tt.in); got != tt.want {
+			t.Errorf("suggestProjectName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateProjectName(t *testing.T) {
+	for _, name := range []string{"my-app", "my_app", "MyApp", "app2", "billing"} {
+		if err := validateProjectName(name, true); err != nil {
+			t.Errorf("validateProjectName(%q): %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"", "2fa", "my.app", "type", "user", "Ünïcode", "-app", strings.Repeat("a", 70)} {
+		if err := validateProjectName(name, true); err == nil {
+			t.Errorf("validateProjectName(%q) succeeded, want an error", name)
+		}
+	}
+}
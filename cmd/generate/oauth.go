@@ -1,10 +1,6 @@
 package generate
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-
 	"github.com/spf13/cobra"
 )
 
@@ -19,30 +15,28 @@ var OAuthCmd = &cobra.Command{
 - User sessions
 - Example routes and middleware
 
-Use "." as project-name to use the current working directory.`,
+Use "." as project-name to use the current working directory.
+The project name is the name of the directory unless --name is given, e.g.
+iron generate oauth ./services/x --name billing. It must start with a letter
+and contain only ASCII letters, digits, dashes and underscores, so that it
+gives valid Go identifiers, module paths, Postgres identifiers and Docker
+Compose project names.
+Use --module to set the Go module path of the generated project.
+Use --dry-run to preview the generated files without writing them, or
+--dry-run=content and --dry-run=txtar to dump their contents.
+
+The target directory may already contain .git, .gitignore, LICENSE or
+README.md. To generate into a directory with other content, choose how
+existing files are handled with --force (overwrite, keeping a .orig
+backup), --skip-existing or --merge (write conflict markers around the lines
+that differ).`,
 	Args: cobra.ExactArgs(1), // Require exactly one argument
 	RunE: func(cmd *cobra.Command, args []string) error {
-		projectName := args[0]
-
-		cwd, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current working directory: %w", err)
-		}
-
-		var fullPath string
-
-		if projectName == "." {
-			fullPath = cwd
-		} else {
-			fullPath = filepath.Join(cwd, projectName)
-		}
-
-		fullPath = filepath.Clean(fullPath)
-
-		return FromTemplate("oauth", fullPath)
+		return runTemplate("oauth", args[0])
 	},
 }
 
 func init() {
+	addTemplateFlags(OAuthCmd)
 	GenerateCmd.AddCommand(OAuthCmd)
 }
@@ -0,0 +1,76 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// pathDelims returns the delimiters of templated file and directory names,
+// the template-wide delimiters of the manifest or {{ and }}
+func (m *Manifest) pathDelims() (string, string) {
+	if len(m.Delimiters) == 2 {
+		return m.Delimiters[0], m.Delimiters[1]
+	}
+	return defaultLeftDelim, defaultRightDelim
+}
+
+// renderPath renders the templated segments of a slash-separated template path,
+// e.g. cmd/{{.ProjectNameKebab}}/main.go, and checks the result stays a
+// relative path inside the project
+func renderPath(relPath string, manifest *Manifest, data templateData) (string, error) {
+	left, right := manifest.pathDelims()
+	if !strings.Contains(relPath, left) {
+		return relPath, nil
+	}
+
+	tmpl, err := template.New(relPath).Delims(left, right).Option("missingkey=error").Funcs(templateFuncs).Parse(relPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse path template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render path template: %w", err)
+	}
+
+	rendered := buf.String()
+	if !fs.ValidPath(rendered) || rendered == "." || strings.ContainsAny(rendered, "\\\n\r") {
+		return "", fmt.Errorf("template path %s renders to '%s', which is empty or leaves the project directory", relPath, rendered)
+	}
+
+	return rendered, nil
+}
+
+// checkPathCollisions makes sure no two template files render to the same path,
+// and that no file is rendered where another file needs a directory. sources
+// holds the template path of each file.
+func checkPathCollisions(files []File, sources []string) error {
+	byPath := make(map[string]string, len(files))
+	for i, file := range files {
+		if other, ok := byPath[file.Path]; ok {
+			return fmt.Errorf("template files %s and %s both render to %s", other, sources[i], file.Path)
+		}
+		byPath[file.Path] = sources[i]
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for p := range byPath {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			if source, ok := byPath[dir]; ok {
+				return fmt.Errorf("template file %s renders to %s, which template file %s needs as a directory", source, dir, byPath[p])
+			}
+		}
+	}
+
+	return nil
+}
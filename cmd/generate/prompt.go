@@ -0,0 +1,186 @@
+package generate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Prompter asks the user for the value of a variable that was not supplied on the command line
+type Prompter interface {
+	// Prompt returns a raw value that passes v.Parse. defaultValue is only
+	// meaningful when hasDefault is true.
+	Prompt(v Variable, defaultValue string, hasDefault bool) (string, error)
+}
+
+// terminalPrompter prompts on a terminal, re-asking until a valid value is given
+type terminalPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// newPrompter returns a terminal prompter, or nil when stdin is not a terminal
+// or prompting was disabled with --no-input
+func newPrompter(noInput bool) Prompter {
+	if noInput || !isTerminal(os.Stdin) {
+		return nil
+	}
+
+	return &terminalPrompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// Prompt asks for a value using a select list for choices, yes/no for booleans
+// and free text input otherwise
+func (p *terminalPrompter) Prompt(v Variable, defaultValue string, hasDefault bool) (string, error) {
+	for {
+		var (
+			raw string
+			err error
+		)
+
+		switch {
+		case len(v.Choices) > 0:
+			raw, err = p.selectChoice(v, defaultValue, hasDefault)
+		case v.Type == TypeBool:
+			raw, err = p.confirm(v, defaultValue, hasDefault)
+		default:
+			raw, err = p.input(v, defaultValue, hasDefault)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := v.Parse(raw); err != nil {
+			_, _ = fmt.Fprintf(p.out, "%s%s%s\n", ColorYellow, err, ColorReset)
+			continue
+		}
+
+		return raw, nil
+	}
+}
+
+// input asks for free text, falling back to the default on an empty answer
+func (p *terminalPrompter) input(v Variable, defaultValue string, hasDefault bool) (string, error) {
+	for {
+		suffix := ""
+		if hasDefault {
+			suffix = fmt.Sprintf(" [%s]", defaultValue)
+		}
+		_, _ = fmt.Fprintf(p.out, "%s%s: ", p.label(v), suffix)
+
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case answer != "":
+			return answer, nil
+		case hasDefault:
+			return defaultValue, nil
+		}
+
+		_, _ = fmt.Fprintf(p.out, "%sA value is required%s\n", ColorYellow, ColorReset)
+	}
+}
+
+// confirm asks a yes/no question
+func (p *terminalPrompter) confirm(v Variable, defaultValue string, hasDefault bool) (string, error) {
+	hint := "y/n"
+	if b, err := strconv.ParseBool(defaultValue); hasDefault && err == nil {
+		hint = "y/N"
+		if b {
+			hint = "Y/n"
+		}
+	}
+
+	for {
+		_, _ = fmt.Fprintf(p.out, "%s [%s]: ", p.label(v), hint)
+
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+
+		switch strings.ToLower(answer) {
+		case "y", "yes", "true":
+			return "true", nil
+		case "n", "no", "false":
+			return "false", nil
+		case "":
+			if hasDefault {
+				return defaultValue, nil
+			}
+		}
+
+		_, _ = fmt.Fprintf(p.out, "%sPlease answer yes or no%s\n", ColorYellow, ColorReset)
+	}
+}
+
+// selectChoice asks to pick one of the declared choices by number or value
+func (p *terminalPrompter) selectChoice(v Variable, defaultValue string, hasDefault bool) (string, error) {
+	_, _ = fmt.Fprintf(p.out, "%s:\n", p.label(v))
+
+	defaultIndex := 0
+	for i, choice := range v.Choices {
+		marker := ""
+		if hasDefault && choice == defaultValue {
+			marker = " (default)"
+			defaultIndex = i + 1
+		}
+		_, _ = fmt.Fprintf(p.out, "  %d) %s%s\n", i+1, choice, marker)
+	}
+
+	for {
+		if defaultIndex > 0 {
+			_, _ = fmt.Fprintf(p.out, "Select [%d]: ", defaultIndex)
+		} else {
+			_, _ = fmt.Fprint(p.out, "Select: ")
+		}
+
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+
+		if answer == "" && defaultIndex > 0 {
+			return v.Choices[defaultIndex-1], nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(v.Choices) {
+			return v.Choices[n-1], nil
+		}
+		if answer != "" {
+			return answer, nil
+		}
+
+		_, _ = fmt.Fprintf(p.out, "%sPlease select one of the options%s\n", ColorYellow, ColorReset)
+	}
+}
+
+// label formats the variable name and help text
+func (p *terminalPrompter) label(v Variable) string {
+	if v.Help == "" {
+		return ColorBlue + v.Name + ColorReset
+	}
+	return fmt.Sprintf("%s%s%s (%s)", ColorBlue, v.Name, ColorReset, v.Help)
+}
+
+// readLine reads a trimmed line of input
+func (p *terminalPrompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
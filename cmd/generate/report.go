@@ -0,0 +1,147 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Report is the machine-readable summary printed by iron generate --output json
+type Report struct {
+	Template        string          `json:"template"`
+	TemplateVersion string          `json:"templateVersion,omitempty"`
+	TemplateSource  string          `json:"templateSource"`
+	Target          string          `json:"target"`
+	ModulePath      string          `json:"modulePath"`
+	Variables       map[string]any  `json:"variables"`
+	Features        map[string]bool `json:"features"`
+	Files           []ReportFile    `json:"files"`
+	Hooks           []ReportHook    `json:"hooks"`
+	Warnings        []string        `json:"warnings"`
+	Timing          ReportTiming    `json:"timing"`
+}
+
+// File actions of a Report
+const (
+	FileCreated     = "created"
+	FileOverwritten = "overwritten"
+	FileSkipped     = "skipped"
+	FileConflict    = "conflict"
+)
+
+// ReportFile describes a generated file. Size and SHA256 are those of the
+// rendered template content.
+type ReportFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	Mode   string `json:"mode"`
+}
+
+// ReportHook describes a post-generation hook. ExitCode is only set for hooks that ran.
+type ReportHook struct {
+	Name       string   `json:"name"`
+	Command    []string `json:"command"`
+	Status     string   `json:"status"`
+	ExitCode   *int     `json:"exitCode,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	DurationMs int64    `json:"durationMs"`
+}
+
+// ReportTiming is how long each phase of the generation took, in milliseconds
+type ReportTiming struct {
+	RenderMs int64 `json:"renderMs"`
+	WriteMs  int64 `json:"writeMs"`
+	HooksMs  int64 `json:"hooksMs"`
+	TotalMs  int64 `json:"totalMs"`
+}
+
+// validateOutput checks the --output format of iron generate
+func validateOutput(output, dryRunMode string) error {
+	switch output {
+	case "", OutputText:
+		return nil
+	case OutputJSON:
+		if dryRunMode != "" {
+			return fmt.Errorf("--output %s cannot be combined with --dry-run - use --dry-run=%s for a machine-readable preview", OutputJSON, DryRunTxtar)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid output format '%s' (valid formats: %s, %s)", output, OutputText, OutputJSON)
+}
+
+// newReport describes a finished generation
+func newReport(fullPath string, project *Project, result *Result, lock *Lockfile, hooks []HookResult, timing ReportTiming) *Report {
+	report := &Report{
+		Template:        project.Template,
+		TemplateVersion: project.Manifest.Version,
+		TemplateSource:  project.Source,
+		Target:          fullPath,
+		ModulePath:      project.Data.ModulePath,
+		Variables:       project.Data.Vars,
+		Features:        project.Data.Features,
+		Files:           make([]ReportFile, 0, len(project.Files)),
+		Hooks:           make([]ReportHook, 0, len(hooks)),
+		Warnings:        []string{},
+		Timing:          timing,
+	}
+
+	actions := map[string]string{}
+	for action, paths := range map[string][]string{
+		FileCreated:     result.Created,
+		FileOverwritten: result.Overwritten,
+		FileSkipped:     result.Skipped,
+		FileConflict:    result.Conflicts,
+	} {
+		for _, p := range paths {
+			actions[p] = action
+		}
+	}
+
+	for _, file := range project.Files {
+		report.Files = append(report.Files, ReportFile{
+			Path:   file.Path,
+			Action: actions[file.Path],
+			Size:   file.Size(),
+			SHA256: lock.Files[file.Path],
+			Mode:   fmt.Sprintf("%04o", file.Mode.Perm()),
+		})
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	if n := len(result.Overwritten); n > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d existing files were overwritten and backed up with %s", n, backupSuffix))
+	}
+	if n := len(result.Conflicts); n > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d files have conflicts - search for <<<<<<< markers and resolve them", n))
+	}
+
+	for _, r := range hooks {
+		hook := ReportHook{
+			Name:       r.Hook.Name,
+			Command:    r.Hook.Run,
+			Status:     r.Status,
+			DurationMs: r.Duration.Milliseconds(),
+		}
+		if r.Err != nil {
+			hook.Error = r.Err.Error()
+		}
+
+		if r.Status == HookSkipped {
+			warning := fmt.Sprintf("hook '%s' skipped: %v", r.Hook.Name, r.Err)
+			if r.Hook.Install != "" {
+				warning += " (install: " + r.Hook.Install + ")"
+			}
+			report.Warnings = append(report.Warnings, warning)
+		} else {
+			exitCode := r.ExitCode
+			hook.ExitCode = &exitCode
+		}
+
+		report.Hooks = append(report.Hooks, hook)
+	}
+
+	return report
+}
@@ -0,0 +1,474 @@
+package generate
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ironlabsdev/iron/internal/routes"
+	"github.com/ironlabsdev/iron/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Layout of the database code of projects generated from the oauth template
+const (
+	schemaDir   = "database/schema"
+	queriesDir  = "database/queries"
+	servicesDir = "web/services"
+	formsDir    = "web/forms"
+	layoutDir   = "views/layout"
+)
+
+var (
+	resourceFields     []string
+	resourceProjectDir string
+	resourceDryRun     bool
+	resourceForce      bool
+)
+
+// ResourceCmd scaffolds a database-backed resource with its CRUD pages
+var ResourceCmd = &cobra.Command{
+	Use:   "resource <name>",
+	Short: "Add a database table with its queries, service, pages and routes to an existing project",
+	Long: `Scaffold create, read, update and delete pages for a new table in a project
+generated from the oauth template. For a resource called post it creates:
+
+  cmd/migrate/migrations/<version>_create_posts.{up,down}.sql
+  database/schema/posts.sql       the table, for sqlc
+  database/queries/posts.sql      list (paginated), count, get, create, update and delete
+  web/services/post.go            PostService wrapping the sqlc queries
+  web/pages/posts.go              the Posts handlers
+  views/posts/*.templ             list, show, new and edit views using layout.App
+  web/forms/forms.go              form helpers shared by every resource, created once
+
+It sets the model name of the posts table to Post in sqlc.yaml, so sqlc does
+not derive another name from the table, and registers the routes in
+Controller.RegisterRoutes:
+
+  GET  /posts              List      GET  /posts/{id}         Show
+  GET  /posts/new          New       GET  /posts/{id}/edit    Edit
+  POST /posts              Create    POST /posts/{id}         Update
+                                     POST /posts/{id}/delete  Delete
+
+Fields are given as name:type, with the types string, text, int, bigint,
+float, bool, time, date, uuid and ref:<table> for a foreign key. Every table
+also gets id, created_at and updated_at columns. sqlc generate and templ
+generate are run afterwards when they are installed.
+
+Example:
+  iron generate resource post --fields title:string,body:text,published:bool,user_id:ref:users`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return AddResource(ResourceOptions{
+			Name:       args[0],
+			Fields:     resourceFields,
+			ProjectDir: resourceProjectDir,
+			DryRun:     resourceDryRun,
+			Force:      resourceForce,
+		})
+	},
+}
+
+func init() {
+	ResourceCmd.Flags().StringSliceVar(&resourceFields, "fields", nil, "Columns as name:type, comma-separated or repeated (required)")
+	ResourceCmd.Flags().StringVarP(&resourceProjectDir, "dir", "C", ".", "Directory of the project")
+	ResourceCmd.Flags().BoolVar(&resourceDryRun, "dry-run", false, "Show the files that would be created and changed without writing them")
+	ResourceCmd.Flags().BoolVar(&resourceForce, "force", false, "Overwrite existing schema, query, service, handler and view files")
+	_ = ResourceCmd.MarkFlagRequired("fields")
+	GenerateCmd.AddCommand(ResourceCmd)
+}
+
+// ResourceOptions configures AddResource
+type ResourceOptions struct {
+	// Name of the resource in the singular, e.g. post or blog_post
+	Name string
+	// Fields are name:type specs
+	Fields     []string
+	ProjectDir string
+	DryRun     bool
+	Force      bool
+}
+
+// fieldType maps a resource field type to its column and its sqlc Go type
+type fieldType struct {
+	SQL string
+	Go  string
+	// Input is the HTML input type, textarea or checkbox
+	Input string
+}
+
+// fieldTypes are the field types of iron generate resource. Every column is
+// NOT NULL, so sqlc maps them to plain Go types where it can.
+var fieldTypes = map[string]fieldType{
+	"string": {SQL: "TEXT", Go: "string", Input: "text"},
+	"text":   {SQL: "TEXT", Go: "string", Input: "textarea"},
+	"int":    {SQL: "INTEGER", Go: "int32", Input: "number"},
+	"bigint": {SQL: "BIGINT", Go: "int64", Input: "number"},
+	"float":  {SQL: "DOUBLE PRECISION", Go: "float64", Input: "number"},
+	"bool":   {SQL: "BOOLEAN", Go: "bool", Input: "checkbox"},
+	"time":   {SQL: "TIMESTAMPTZ", Go: "pgtype.Timestamptz", Input: "datetime-local"},
+	"date":   {SQL: "DATE", Go: "pgtype.Date", Input: "date"},
+	"uuid":   {SQL: "UUID", Go: "pgtype.UUID", Input: "text"},
+	"ref":    {SQL: "UUID", Go: "pgtype.UUID", Input: "text"},
+}
+
+// resourceColumns are added to every resource table
+var resourceColumns = []string{"id", "created_at", "updated_at"}
+
+// resourceData is the data of the resource component skeletons
+type resourceData struct {
+	ModulePath string
+	// Name is the sqlc model, e.g. BlogPost
+	Name string
+	// Plural is the handler type, e.g. BlogPosts
+	Plural string
+	// Var and PluralVar are variable names, e.g. blogPost and blogPosts
+	Var       string
+	PluralVar string
+	// Title and PluralTitle are headings, e.g. Blog Posts
+	Title       string
+	PluralTitle string
+	// Label and PluralLabel are used in sentences, e.g. blog posts
+	Label       string
+	PluralLabel string
+	Table       string
+	Path        string
+	// ViewsPackage is the package of the views, e.g. blogposts
+	ViewsPackage string
+	Fields       []resourceField
+}
+
+// resourceField is a column of a resource
+type resourceField struct {
+	Column string
+	// GoName is the sqlc field name, e.g. UserID
+	GoName string
+	Title  string
+	Kind   string
+	GoType string
+	Input  string
+	// SQL is the column type with its constraints
+	SQL string
+}
+
+// ColumnList lists the columns set by the create query
+func (d resourceData) ColumnList() string {
+	columns := make([]string, len(d.Fields))
+	for i, f := range d.Fields {
+		columns[i] = f.Column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Placeholders lists the parameters of the create query, $1, $2...
+func (d resourceData) Placeholders() string {
+	params := make([]string, len(d.Fields))
+	for i := range d.Fields {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return strings.Join(params, ", ")
+}
+
+// Assignments lists the SET clauses of the update query, whose $1 is the id
+func (d resourceData) Assignments() string {
+	var assignments []string
+	for i, f := range d.Fields {
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", f.Column, i+2))
+	}
+	assignments = append(assignments, "updated_at = NOW()")
+	return strings.Join(assignments, ",\n    ")
+}
+
+// AddResource scaffolds a resource in an existing project
+func AddResource(opts ResourceOptions) error {
+	project, err := findProject(opts.ProjectDir)
+	if err != nil {
+		return err
+	}
+	if err := project.require(path.Join(pagesDir, "pages.go"), layoutDir, schemaDir, queriesDir, servicesDir, sqlcConfigFile); err != nil {
+		return err
+	}
+
+	data, err := newResourceData(project, opts.Name, opts.Fields)
+	if err != nil {
+		return err
+	}
+
+	// The handler file about to be overwritten with --force does not count as a conflict
+	handlerFile := path.Join(pagesDir, utils.ToSnakeCase(data.Plural)+".go")
+	types, _, err := handlerDeclarations(project, map[string]bool{handlerFile: opts.Force})
+	if err != nil {
+		return err
+	}
+	if types[data.Plural] {
+		return fmt.Errorf("%s already declares a %s type", pagesDir, data.Plural)
+	}
+
+	var changes []componentChange
+
+	// The migration never overwrites anything, as its version is new. Projects
+	// generated without the migrations feature only get the sqlc schema.
+	migration := data.migrationData()
+	if project.exists(migrationsDir) {
+		migrations, err := readMigrations(project, migrationsDir)
+		if err != nil {
+			return err
+		}
+		_, version := nextMigrationVersion(migrations, time.Now().UTC())
+		migrationChanges, err := project.migrationChanges(migrationsDir, version+"_"+migration.Name, migration)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, migrationChanges...)
+	}
+
+	schema, err := renderComponent("migration/up.sql.tmpl", migration)
+	if err != nil {
+		return err
+	}
+	change, err := project.newComponentChange(path.Join(schemaDir, data.Table+".sql"), []byte(strings.TrimSpace(string(schema))+"\n"), opts.Force)
+	if err != nil {
+		return err
+	}
+	changes = append(changes, change)
+
+	views := path.Join("views", data.ViewsPackage)
+	for _, c := range []struct{ skeleton, relPath string }{
+		{"resource/queries.sql.tmpl", path.Join(queriesDir, data.Table+".sql")},
+		{"resource/service.go.tmpl", path.Join(servicesDir, utils.ToSnakeCase(data.Name)+".go")},
+		{"resource/handler.go.tmpl", handlerFile},
+		{"resource/list.templ.tmpl", path.Join(views, "list.templ")},
+		{"resource/show.templ.tmpl", path.Join(views, "show.templ")},
+		{"resource/form.templ.tmpl", path.Join(views, "form.templ")},
+	} {
+		content, err := renderComponent(c.skeleton, data)
+		if err != nil {
+			return err
+		}
+		change, err := project.newComponentChange(c.relPath, content, opts.Force)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, change)
+	}
+
+	// The form helpers are shared by every resource and belong to the project once created
+	for _, c := range []struct{ skeleton, relPath string }{
+		{"resource/forms.go.tmpl", path.Join(formsDir, "forms.go")},
+		{"resource/forms_test.go.tmpl", path.Join(formsDir, "forms_test.go")},
+	} {
+		if project.exists(c.relPath) {
+			continue
+		}
+		content, err := renderComponent(c.skeleton, data)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, componentChange{File: File{Path: c.relPath, Content: content, Mode: modeRegular}})
+	}
+
+	// sqlc would name the model of some tables differently, e.g. Ty for ties
+	sqlcConfig, err := os.ReadFile(project.path(sqlcConfigFile))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", sqlcConfigFile, err)
+	}
+	sqlcConfig, err = addSqlcModel(sqlcConfig, data.Table, data.Name)
+	if err != nil {
+		return err
+	}
+	change, err = project.newComponentChange(sqlcConfigFile, sqlcConfig, true)
+	if err != nil {
+		return err
+	}
+	changes = append(changes, change)
+
+	router, routerFile, err := addResourceRoutes(project, data)
+	if err != nil {
+		return err
+	}
+	change, err = project.newComponentChange(routerFile, router, true)
+	if err != nil {
+		return err
+	}
+	changes = append(changes, change)
+
+	if opts.DryRun {
+		fmt.Printf("%sDry run: %s resource on %s (no files written)%s\n\n", ColorBlue, data.Name, data.Path, ColorReset)
+		printComponentChanges(os.Stdout, changes, true)
+		return nil
+	}
+
+	if err := project.applyComponentChanges(changes); err != nil {
+		return fmt.Errorf("failed to add resource: %w", err)
+	}
+
+	printComponentChanges(os.Stdout, changes, false)
+	fmt.Printf("%s✓ Added the %s resource on %s%s\n", ColorGreen, data.Name, data.Path, ColorReset)
+
+	project.runTool(sqlcInstall, "sqlc", "generate")
+	project.runTool(templInstall, "templ", "generate", "-path", views)
+	if project.exists(migrationsDir) {
+		fmt.Printf("%s→ Apply the migration with: go run ./cmd/migrate up%s\n", ColorBlue, ColorReset)
+	} else {
+		fmt.Printf("%s! %s not found - create the %s table from %s/%s.sql yourself%s\n", ColorYellow, migrationsDir, data.Table, schemaDir, data.Table, ColorReset)
+	}
+	return nil
+}
+
+// newResourceData validates the name and fields and derives the skeleton data
+func newResourceData(project *existingProject, name string, fieldSpecs []string) (resourceData, error) {
+	singular := utils.ToSnakeCase(utils.Singularize(name))
+	plural := utils.Pluralize(singular)
+
+	data := resourceData{
+		ModulePath:   project.ModulePath,
+		Name:         utils.ToPascalCase(singular),
+		Plural:       utils.ToPascalCase(plural),
+		Var:          utils.ToCamelCase(singular),
+		PluralVar:    utils.ToCamelCase(plural),
+		Title:        utils.ToTitleCase(singular),
+		PluralTitle:  utils.ToTitleCase(plural),
+		Label:        strings.ReplaceAll(singular, "_", " "),
+		PluralLabel:  strings.ReplaceAll(plural, "_", " "),
+		Table:        plural,
+		Path:         "/" + utils.ToKebabCase(plural),
+		ViewsPackage: strings.ReplaceAll(plural, "_", ""),
+	}
+	if !sqlIdentifierRe.MatchString(data.Table) || !token.IsIdentifier(data.Var) || token.IsKeyword(data.Var) {
+		return data, fmt.Errorf("invalid resource name '%s' - use letters, digits, dashes or underscores, starting with a letter", name)
+	}
+	// The views package must not be shadowed by the variables of the handlers
+	if data.ViewsPackage == strings.ToLower(data.Var) {
+		data.ViewsPackage += "views"
+	}
+
+	if len(fieldSpecs) == 0 {
+		return data, fmt.Errorf("a resource needs at least one field - add --fields name:type,...")
+	}
+
+	seen := map[string]bool{}
+	for _, spec := range fieldSpecs {
+		field, err := parseFieldSpec(spec)
+		if err != nil {
+			return data, err
+		}
+		if slices.Contains(resourceColumns, field.Column) {
+			return data, fmt.Errorf("field '%s' is reserved - %s are added to every resource", field.Column, strings.Join(resourceColumns, ", "))
+		}
+		if seen[field.Column] {
+			return data, fmt.Errorf("duplicate field '%s'", field.Column)
+		}
+		seen[field.Column] = true
+		data.Fields = append(data.Fields, field)
+	}
+
+	return data, nil
+}
+
+// parseFieldSpec parses name:type or name:ref:table
+func parseFieldSpec(spec string) (resourceField, error) {
+	column, kind, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	kind, ref, _ := strings.Cut(kind, ":")
+
+	if !sqlIdentifierRe.MatchString(column) {
+		return resourceField{}, fmt.Errorf("invalid field name '%s' - use lower-case letters, digits and underscores", column)
+	}
+	ft, ok := fieldTypes[kind]
+	if !ok {
+		return resourceField{}, fmt.Errorf("invalid type '%s' for field '%s' (valid types: string, text, int, bigint, float, bool, time, date, uuid, ref:<table>)", kind, column)
+	}
+
+	sqlType := ft.SQL + " NOT NULL"
+	switch {
+	case kind == "ref" && !sqlIdentifierRe.MatchString(ref):
+		return resourceField{}, fmt.Errorf("field '%s' needs the table it refers to, e.g. %s:ref:users", column, column)
+	case kind == "ref":
+		sqlType += fmt.Sprintf(" REFERENCES %s (id) ON DELETE CASCADE", ref)
+	case ref != "":
+		return resourceField{}, fmt.Errorf("invalid field '%s' - only ref fields name a table", spec)
+	}
+
+	return resourceField{
+		Column: column,
+		GoName: sqlcFieldName(column),
+		Title:  utils.ToTitleCase(column),
+		Kind:   kind,
+		GoType: ft.Go,
+		Input:  ft.Input,
+		SQL:    sqlType,
+	}, nil
+}
+
+// sqlcFieldName is the Go name sqlc gives a column: each word capitalised, with id written ID
+func sqlcFieldName(column string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(column, "_") {
+		if word == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		if word != "" {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// migrationData describes the CREATE TABLE of the resource
+func (d resourceData) migrationData() migrationData {
+	table := &migrationTable{Name: d.Table, Width: len("created_at")}
+	for _, f := range d.Fields {
+		table.Columns = append(table.Columns, migrationColumn{Table: d.Table, Name: f.Column, Type: f.SQL})
+		table.Width = max(table.Width, len(f.Column))
+	}
+	return migrationData{Name: "create_" + d.Table, CreateTable: table}
+}
+
+// resourceRoutes are the routes of a resource, relative to its path
+var resourceRoutes = []struct{ method, suffix, handler string }{
+	{"GET", "", "List"},
+	{"GET", "/new", "New"},
+	{"POST", "", "Create"},
+	{"GET", "/{id}", "Show"},
+	{"GET", "/{id}/edit", "Edit"},
+	{"POST", "/{id}", "Update"},
+	{"POST", "/{id}/delete", "Delete"},
+}
+
+// addResourceRoutes declares the resource handler in RegisterRoutes and
+// registers its routes, returning the new router source with its project path
+func addResourceRoutes(project *existingProject, data resourceData) ([]byte, string, error) {
+	router, routerFile, err := project.router()
+	if err != nil {
+		return nil, "", err
+	}
+
+	constructor := "pages.New" + data.Plural
+	handlerVar, ok := router.HandlerVar(constructor)
+	if !ok {
+		handlerVar = data.PluralVar + "Handler"
+		if err := router.DeclareHandler(handlerVar, constructor, "Logger", "Pool"); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, r := range resourceRoutes {
+		route := routes.Route{Method: r.method, Path: data.Path + r.suffix, Handler: handlerVar + "." + r.handler}
+		if err := router.Add(route); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, importPath := range []string{"net/http", project.ModulePath + "/" + pagesDir, project.ModulePath + "/" + requestlogPath} {
+		if err := router.AddImport(importPath); err != nil {
+			return nil, "", err
+		}
+	}
+
+	content, err := router.Bytes()
+	return content, routerFile, err
+}
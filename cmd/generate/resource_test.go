@@ -0,0 +1,56 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResourceDataNames(t *testing.T) {
+	tests := []struct {
+		name, table, typ string
+	}{
+		{"post", "posts", "Post"},
+		{"blog_post", "blog_posts", "BlogPost"},
+		{"category", "categories", "Category"},
+		{"person", "people", "Person"},
+		{"quiz", "quizzes", "Quiz"},
+		{"cookie", "cookies", "Cookie"},
+		// sqlc would name the models of these tables Ty, Leafe and Datum
+		{"tie", "ties", "Tie"},
+		{"leaf", "leaves", "Leaf"},
+		{"data", "data", "Data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := newResourceData(&existingProject{ModulePath: "example.com/app"}, tt.name, []string{"title:string"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.Table != tt.table || data.Name != tt.typ {
+				t.Errorf("got table %q and name %q, want %q and %q", data.Table, data.Name, tt.table, tt.typ)
+			}
+
+			// The skeletons refer to the model named in sqlc.yaml
+			for _, skeleton := range []string{"resource/service.go.tmpl", "resource/show.templ.tmpl"} {
+				content, err := renderComponent(skeleton, data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(content), "db."+tt.typ+")") {
+					t.Errorf("%s does not use db.%s", skeleton, tt.typ)
+				}
+			}
+
+			config, err := addSqlcModel([]byte(oauthSqlcConfig), data.Table, data.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{"- " + tt.table + "\n", tt.table + ": " + tt.typ + "\n"} {
+				if !strings.Contains(string(config), want) {
+					t.Errorf("sqlc.yaml does not contain %q:\n%s", want, config)
+				}
+			}
+		})
+	}
+}
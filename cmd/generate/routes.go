@@ -0,0 +1,258 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ironlabsdev/iron/internal/routes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	routesProjectDir string
+	routesOutput     string
+	routesDryRun     bool
+	routesUse        string
+	routesImports    []string
+)
+
+// RoutesCmd groups the commands reading and editing the routes of a project
+var RoutesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "List, add and remove the routes and middleware of a project",
+	Long: `Read and edit the routes registered in Controller.RegisterRoutes and the
+middleware registered in Controller.RegisterUses of a project generated from
+the oauth template.
+
+The router file is found by loading the packages of the project, and it is
+edited through its syntax tree: the comments and layout of the rest of the
+file are kept.`,
+}
+
+// RoutesListCmd lists the routes and middleware of a project
+var RoutesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the routes and middleware of a project",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if routesOutput != OutputText && routesOutput != OutputJSON {
+			return fmt.Errorf("invalid output format '%s' (valid formats: %s, %s)", routesOutput, OutputText, OutputJSON)
+		}
+
+		project, router, relPath, err := loadRouter(routesProjectDir)
+		if err != nil {
+			return err
+		}
+
+		table := RouteTable{File: relPath, Middleware: router.Middleware(), Routes: router.Routes()}
+		if table.Middleware == nil {
+			table.Middleware = []routes.Middleware{}
+		}
+		if table.Routes == nil {
+			table.Routes = []routes.Route{}
+		}
+
+		if routesOutput == OutputJSON {
+			return printJSON(os.Stdout, table)
+		}
+		printRouteTable(os.Stdout, project, table)
+		return nil
+	},
+}
+
+// RoutesAddCmd registers a route or a middleware
+var RoutesAddCmd = &cobra.Command{
+	Use:   "add <method> <path> <handler> | --use <middleware>",
+	Short: "Register a route or a middleware",
+	Long: `Register a route in RegisterRoutes, wrapped with requestlog.NewHandler and
+placed after the other routes of the same handler variable:
+
+  iron routes add GET /reports pageHandler.Reports
+
+The method ANY registers a route matching every method with Router.Handle
+instead of Router.Method:
+
+  iron routes add ANY /webhook webhookHandler.Handle
+
+Or register a middleware after the others in RegisterUses:
+
+  iron routes add --use middleware.Recoverer --import github.com/go-chi/chi/v5/middleware`,
+	Args: routesArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editRoutes(func(project *existingProject, router *routes.Router) (string, error) {
+			if routesUse != "" {
+				return "Registered middleware " + routesUse, router.Use(routesUse)
+			}
+
+			route := routes.Route{Method: strings.ToUpper(args[0]), Path: args[1], Handler: args[2]}
+			if !strings.HasPrefix(route.Path, "/") {
+				return "", fmt.Errorf("invalid route path '%s' - it must start with /", route.Path)
+			}
+			if err := router.Add(route); err != nil {
+				return "", err
+			}
+			imports := []string{project.ModulePath + "/" + requestlogPath}
+			if route.Method != routes.AnyMethod {
+				imports = append(imports, "net/http")
+			}
+			for _, importPath := range imports {
+				if err := router.AddImport(importPath); err != nil {
+					return "", err
+				}
+			}
+			return fmt.Sprintf("Registered %s handled by %s", route, route.Handler), nil
+		})
+	},
+}
+
+// RoutesRemoveCmd unregisters a route or a middleware
+var RoutesRemoveCmd = &cobra.Command{
+	Use:   "remove <method> <path> | --use <middleware>",
+	Short: "Unregister a route or a middleware",
+	Long: `Unregister a route from RegisterRoutes or a middleware from RegisterUses.
+Handler variables and imports that were only used by it are removed as well.
+The handler itself is left in place.`,
+	Aliases: []string{"rm"},
+	Args:    routesArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editRoutes(func(project *existingProject, router *routes.Router) (string, error) {
+			if routesUse != "" {
+				return "Unregistered middleware " + routesUse, router.RemoveUse(routesUse)
+			}
+
+			route, err := router.Remove(strings.ToUpper(args[0]), args[1])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Unregistered %s handled by %s", route, route.Handler), nil
+		})
+	},
+}
+
+func init() {
+	RoutesListCmd.Flags().StringVarP(&routesOutput, "output", "o", OutputText, "Output format: text or json")
+	RoutesAddCmd.Flags().StringSliceVar(&routesImports, "import", nil, "Package to import in the router file, e.g. for the middleware (repeatable)")
+
+	for _, cmd := range []*cobra.Command{RoutesAddCmd, RoutesRemoveCmd} {
+		cmd.Flags().StringVar(&routesUse, "use", "", "Middleware expression instead of a route, e.g. middleware.Recoverer")
+		cmd.Flags().BoolVar(&routesDryRun, "dry-run", false, "Show the change to the router file without writing it")
+	}
+
+	for _, cmd := range []*cobra.Command{RoutesListCmd, RoutesAddCmd, RoutesRemoveCmd} {
+		cmd.Flags().StringVarP(&routesProjectDir, "dir", "C", ".", "Directory of the project")
+		RoutesCmd.AddCommand(cmd)
+	}
+}
+
+// routesArgs expects n arguments starting with an HTTP method, or none with --use
+func routesArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("use") {
+			return cobra.NoArgs(cmd, args)
+		}
+
+		if len(args) != n {
+			return fmt.Errorf("expected %d arguments (usage: iron routes %s), got %d", n, cmd.Use, len(args))
+		}
+		if method := strings.ToUpper(args[0]); !routes.ValidMethod(method) && method != routes.AnyMethod {
+			return fmt.Errorf("invalid HTTP method '%s'", args[0])
+		}
+		return nil
+	}
+}
+
+// RouteTable is the routes and middleware of a project as shown by iron routes list
+type RouteTable struct {
+	// File is the router file, relative to the project
+	File       string              `json:"file"`
+	Middleware []routes.Middleware `json:"middleware"`
+	Routes     []routes.Route      `json:"routes"`
+}
+
+// printRouteTable prints the middleware and routes of a project
+func printRouteTable(w io.Writer, project *existingProject, table RouteTable) {
+	_, _ = fmt.Fprintf(w, "%s%s%s (%s)\n", ColorGreen, table.File, ColorReset, project.ModulePath)
+
+	_, _ = fmt.Fprintf(w, "\nMiddleware (%d):\n", len(table.Middleware))
+	for _, m := range table.Middleware {
+		_, _ = fmt.Fprintf(w, "  %s\n", m.Expr)
+	}
+
+	_, _ = fmt.Fprintf(w, "\nRoutes (%d):\n", len(table.Routes))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, route := range table.Routes {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+	}
+	_ = tw.Flush()
+}
+
+// loadRouter finds the project containing dir and its router file
+func loadRouter(dir string) (*existingProject, *routes.Router, string, error) {
+	project, err := findProject(dir)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	router, relPath, err := project.router()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return project, router, relPath, nil
+}
+
+// router loads the file declaring the router Controller, returning it with its slash-separated project path
+func (p *existingProject) router() (*routes.Router, string, error) {
+	router, err := routes.Load(p.Root)
+	if err != nil {
+		return nil, "", err
+	}
+
+	relPath, err := filepath.Rel(p.Root, router.Filename)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve %s: %w", router.Filename, err)
+	}
+	return router, filepath.ToSlash(relPath), nil
+}
+
+// editRoutes applies edit to the router file of the project, or previews it with --dry-run
+func editRoutes(edit func(project *existingProject, router *routes.Router) (string, error)) error {
+	project, router, relPath, err := loadRouter(routesProjectDir)
+	if err != nil {
+		return err
+	}
+
+	summary, err := edit(project, router)
+	if err != nil {
+		return err
+	}
+	for _, importPath := range routesImports {
+		if err := router.AddImport(importPath); err != nil {
+			return err
+		}
+	}
+
+	content, err := router.Bytes()
+	if err != nil {
+		return err
+	}
+	change, err := project.newComponentChange(relPath, content, true)
+	if err != nil {
+		return err
+	}
+
+	if routesDryRun {
+		fmt.Printf("%sDry run: %s (no files written)%s\n\n", ColorBlue, summary, ColorReset)
+		printComponentChanges(os.Stdout, []componentChange{change}, true)
+		return nil
+	}
+
+	if err := project.applyComponentChanges([]componentChange{change}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", relPath, err)
+	}
+	fmt.Printf("%s✓ %s%s\n", ColorGreen, summary, ColorReset)
+	return nil
+}
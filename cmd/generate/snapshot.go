@@ -0,0 +1,115 @@
+package generate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SnapshotPath is where a copy of the template is kept inside generated projects,
+// so later upgrades can re-render exactly what was originally generated
+const SnapshotPath = ".iron/template.tar.gz"
+
+// SnapshotSource is the source name of templates read back from a snapshot
+const SnapshotSource = "snapshot"
+
+// writeSnapshot archives every file of the template that is not ignored, with its mode, into the project directory
+func writeSnapshot(fullPath string, fsys fs.FS) error {
+	destPath := filepath.Join(fullPath, filepath.FromSlash(SnapshotPath))
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	f, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", destPath, err)
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = walkTemplateFiles(fsys, func(filePath string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header := &tar.Header{Name: filePath, Mode: int64(info.Mode().Perm()), Size: info.Size(), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		src, err := fsys.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer func(src fs.File) {
+			_ = src.Close()
+		}(src)
+
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive template: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to archive template: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to archive template: %w", err)
+	}
+
+	return f.Close()
+}
+
+// ReadSnapshot loads the template snapshot of the project in dir
+func ReadSnapshot(dir, templateName string) (*Template, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(SnapshotPath)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no template snapshot found at %s - the project was generated by an older iron", SnapshotPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open template snapshot: %w", err)
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template snapshot: %w", err)
+	}
+
+	files := snapshotFS{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid template snapshot: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !fs.ValidPath(header.Name) {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template snapshot: %w", err)
+		}
+		files[header.Name] = snapshotFile{data: content, mode: fs.FileMode(header.Mode).Perm()}
+	}
+
+	return &Template{Name: templateName, Source: SnapshotSource, FS: files}, nil
+}
@@ -0,0 +1,134 @@
+package generate
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// snapshotFS is a read-only fs.FS holding the files of a template snapshot in
+// memory, keyed by their slash-separated path. Directories are implied by the
+// paths of the files they contain.
+type snapshotFS map[string]snapshotFile
+
+// snapshotFile is the content and permission bits of a snapshot file
+type snapshotFile struct {
+	data []byte
+	mode fs.FileMode
+}
+
+// Open opens a file or a directory of the snapshot
+func (s snapshotFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if file, ok := s[name]; ok {
+		info := snapshotInfo{name: path.Base(name), size: int64(len(file.data)), mode: file.mode}
+		return &snapshotHandle{Reader: bytes.NewReader(file.data), info: info}, nil
+	}
+
+	entries, err := s.ReadDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &snapshotDir{info: snapshotInfo{name: path.Base(name), mode: fs.ModeDir | 0o755}, entries: entries}, nil
+}
+
+// ReadDir lists the files and directories directly inside the directory name, sorted by name
+func (s snapshotFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for filePath, file := range s {
+		rest, ok := strings.CutPrefix(filePath, prefix)
+		if !ok {
+			continue
+		}
+		child, _, isDir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+
+		info := snapshotInfo{name: child, size: int64(len(file.data)), mode: file.mode}
+		if isDir {
+			info = snapshotInfo{name: child, mode: fs.ModeDir | 0o755}
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+
+	if len(entries) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// snapshotInfo describes a file or a directory of a snapshotFS
+type snapshotInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i snapshotInfo) Name() string       { return i.name }
+func (i snapshotInfo) Size() int64        { return i.size }
+func (i snapshotInfo) Mode() fs.FileMode  { return i.mode }
+func (i snapshotInfo) ModTime() time.Time { return time.Time{} }
+func (i snapshotInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i snapshotInfo) Sys() any           { return nil }
+
+// snapshotHandle is an open snapshot file
+type snapshotHandle struct {
+	*bytes.Reader
+	info snapshotInfo
+}
+
+func (h *snapshotHandle) Stat() (fs.FileInfo, error) { return h.info, nil }
+func (h *snapshotHandle) Close() error               { return nil }
+
+// snapshotDir is an open snapshot directory
+type snapshotDir struct {
+	info    snapshotInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *snapshotDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *snapshotDir) Close() error               { return nil }
+
+func (d *snapshotDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir returns the next n entries of the directory, or all remaining ones when n <= 0
+func (d *snapshotDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,19 @@
+package generate
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestSnapshotFS(t *testing.T) {
+	fsys := snapshotFS{
+		"go.mod":            {data: []byte("module oauth\n"), mode: 0o644},
+		"main.go":           {data: []byte("package main\n"), mode: 0o644},
+		"web/pages/home.go": {data: []byte("package pages\n"), mode: 0o644},
+		"bin/setup.sh":      {data: []byte("#!/bin/sh\n"), mode: 0o755},
+	}
+
+	if err := fstest.TestFS(fsys, "go.mod", "main.go", "web/pages/home.go", "bin/setup.sh"); err != nil {
+		t.Fatal(err)
+	}
+}
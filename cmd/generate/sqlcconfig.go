@@ -0,0 +1,124 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sqlcConfigFile is the sqlc configuration of projects generated from the oauth template
+const sqlcConfigFile = "sqlc.yaml"
+
+// addSqlcModel names the model sqlc generates for table in the sqlc
+// configuration content. sqlc derives model names by singularizing the table
+// with rules of its own, which do not always give back the resource name: the
+// table is excluded from them and renamed to model instead. Comments and the
+// other settings are kept.
+func addSqlcModel(content []byte, table, model string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", sqlcConfigFile, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid %s: expected a mapping", sqlcConfigFile)
+	}
+	root := doc.Content[0]
+
+	if version := mappingValue(root, "version"); version == nil || version.Value != "2" {
+		return nil, fmt.Errorf("%s must use version 2 of the configuration format", sqlcConfigFile)
+	}
+
+	goGen := sqlcGoGen(mappingValue(root, "sql"))
+	if goGen == nil {
+		return nil, fmt.Errorf("%s has no sql entry generating Go code for %s", sqlcConfigFile, schemaDir)
+	}
+
+	excluded := mappingEntry(goGen, "inflection_exclude_table_names", yaml.SequenceNode)
+	if !slices.ContainsFunc(excluded.Content, func(n *yaml.Node) bool { return n.Value == table }) {
+		excluded.Content = append(excluded.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: table})
+	}
+
+	rename := mappingEntry(goGen, "rename", yaml.MappingNode)
+	if current := mappingValue(rename, table); current != nil {
+		current.Value = model
+	} else {
+		rename.Content = append(rename.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: table},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: model})
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", sqlcConfigFile, err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", sqlcConfigFile, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sqlcGoGen returns the gen.go settings of the sql entry reading schemaDir,
+// or of the only entry generating Go code
+func sqlcGoGen(sql *yaml.Node) *yaml.Node {
+	if sql == nil || sql.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	var candidates []*yaml.Node
+	for _, entry := range sql.Content {
+		goGen := mappingValue(mappingValue(entry, "gen"), "go")
+		if goGen == nil || goGen.Kind != yaml.MappingNode {
+			continue
+		}
+
+		schema := mappingValue(entry, "schema")
+		switch {
+		case schema == nil:
+		case schema.Value == schemaDir:
+			return goGen
+		case schema.Kind == yaml.SequenceNode && slices.ContainsFunc(schema.Content, func(n *yaml.Node) bool { return n.Value == schemaDir }):
+			return goGen
+		}
+		candidates = append(candidates, goGen)
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingEntry returns the value of key in a mapping node, adding an empty
+// node of the given kind when the key is missing or null
+func mappingEntry(node *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	value := mappingValue(node, key)
+	if value != nil && value.Kind == kind {
+		return value
+	}
+	if value != nil {
+		// A null value, e.g. rename: with nothing after it
+		*value = yaml.Node{Kind: kind}
+		return value
+	}
+
+	value = &yaml.Node{Kind: kind}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
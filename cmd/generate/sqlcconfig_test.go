@@ -0,0 +1,72 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+)
+
+const oauthSqlcConfig = `version: "2"
+sql:
+  - engine: "postgresql"
+    queries: "database/queries"
+    schema: "database/schema"
+    gen:
+      go:
+        package: "db"
+        out: "database/generated"
+        sql_package: "pgx/v5"
+`
+
+func TestAddSqlcModel(t *testing.T) {
+	config, err := addSqlcModel([]byte(oauthSqlcConfig), "ties", "Tie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err = addSqlcModel(config, "leaves", "Leaf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Adding a table again does not repeat it
+	config, err = addSqlcModel(config, "ties", "Tie")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := oauthSqlcConfig + `        inflection_exclude_table_names:
+          - ties
+          - leaves
+        rename:
+          ties: Tie
+          leaves: Leaf
+`
+	if string(config) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", config, want)
+	}
+}
+
+func TestAddSqlcModelKeepsComments(t *testing.T) {
+	content := strings.Replace(oauthSqlcConfig, "sql:\n", "# Generated code lives in database/generated\nsql:\n", 1)
+	config, err := addSqlcModel([]byte(content), "posts", "Post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(config), "# Generated code lives in database/generated\n") {
+		t.Errorf("the comment was dropped:\n%s", config)
+	}
+}
+
+func TestAddSqlcModelErrors(t *testing.T) {
+	tests := map[string]string{
+		"version 1":   "version: \"1\"\npackages: []\n",
+		"no go code":  "version: \"2\"\nsql:\n  - engine: postgresql\n    schema: database/schema\n",
+		"not yaml":    "version: [\n",
+		"not a map":   "- a\n",
+		"two entries": "version: \"2\"\nsql:\n  - schema: a\n    gen: {go: {package: a}}\n  - schema: b\n    gen: {go: {package: b}}\n",
+	}
+
+	for name, content := range tests {
+		if _, err := addSqlcModel([]byte(content), "posts", "Post"); err == nil {
+			t.Errorf("%s: addSqlcModel succeeded, want an error", name)
+		}
+	}
+}
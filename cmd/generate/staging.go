@@ -0,0 +1,252 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// errInterrupted is returned when generation is cancelled with Ctrl-C
+var errInterrupted = errors.New("generation interrupted, the target directory was left unchanged")
+
+// transaction stages generated files in a temporary directory next to the
+// target and moves them into place on commit. Anything that fails, or an
+// interrupt, leaves the target directory as it was.
+type transaction struct {
+	target string
+	// root is the temporary directory holding files and replaced
+	root string
+	// files holds the staged project, laid out like the target
+	files string
+	// replaced keeps the target files overwritten by the commit until it succeeds
+	replaced string
+	// backups are staged paths whose existing file is kept with backupSuffix
+	backups map[string]bool
+	// undo reverts the steps of a commit in progress, in reverse order
+	undo []func() error
+}
+
+// newTransaction creates the staging directory on the same filesystem as target,
+// so files can be renamed into place
+func newTransaction(target string) (*transaction, error) {
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	root, err := os.MkdirTemp(parent, "."+filepath.Base(target)+".iron-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	tx := &transaction{
+		target:   target,
+		root:     root,
+		files:    filepath.Join(root, "files"),
+		replaced: filepath.Join(root, "replaced"),
+		backups:  map[string]bool{},
+	}
+
+	if err := os.Mkdir(tx.files, 0755); err != nil {
+		_ = os.RemoveAll(root)
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	return tx, nil
+}
+
+// stage writes a generated file into the staging directory
+func (t *transaction) stage(file File) error {
+	if err := writeProjectFile(filepath.Join(t.files, filepath.FromSlash(file.Path)), file); err != nil {
+		return fmt.Errorf("failed to generate %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// backup keeps the existing target file at relPath as a backupSuffix copy on commit
+func (t *transaction) backup(relPath string) {
+	t.backups[filepath.FromSlash(relPath)] = true
+}
+
+// commit moves the staged files into the target directory. A new target is
+// renamed into place in one step, otherwise files are moved one by one and
+// every move is undone if a later one fails or ctx is cancelled.
+func (t *transaction) commit(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return errInterrupted
+	}
+
+	if _, err := os.Lstat(t.target); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(t.files, t.target); err != nil {
+			return fmt.Errorf("failed to move project into place: %w", err)
+		}
+		return nil
+	}
+
+	var paths []string
+	err := filepath.WalkDir(t.files, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(t.files, filePath)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, relPath)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read staging directory: %w", err)
+	}
+	sort.Strings(paths)
+
+	for _, relPath := range paths {
+		if ctx.Err() != nil {
+			return t.revert(errInterrupted)
+		}
+		if err := t.move(relPath); err != nil {
+			return t.revert(fmt.Errorf("failed to move %s into place: %w", filepath.ToSlash(relPath), err))
+		}
+	}
+
+	t.undo = nil
+	return nil
+}
+
+// move renames a staged file into the target, moving any existing file aside first
+func (t *transaction) move(relPath string) error {
+	dest := filepath.Join(t.target, relPath)
+
+	if err := t.mkdirs(filepath.Dir(dest)); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(dest); err == nil {
+		aside := filepath.Join(t.replaced, relPath)
+		if t.backups[relPath] {
+			if aside, err = t.backupPath(relPath); err != nil {
+				return err
+			}
+		} else if err := os.MkdirAll(filepath.Dir(aside), 0755); err != nil {
+			return err
+		}
+
+		if err := os.Rename(dest, aside); err != nil {
+			return err
+		}
+		t.undo = append(t.undo, func() error { return os.Rename(aside, dest) })
+	}
+
+	if err := os.Rename(filepath.Join(t.files, relPath), dest); err != nil {
+		return err
+	}
+	t.undo = append(t.undo, func() error { return os.Remove(dest) })
+
+	return nil
+}
+
+// backupPath returns the first of relPath.orig, relPath.orig.1, ... that is
+// neither in the target nor staged, so earlier backups are never overwritten
+func (t *transaction) backupPath(relPath string) (string, error) {
+	for i := 0; ; i++ {
+		name := relPath + backupSuffix
+		if i > 0 {
+			name += "." + strconv.Itoa(i)
+		}
+
+		taken := false
+		for _, dir := range []string{t.target, t.files} {
+			_, err := os.Lstat(filepath.Join(dir, name))
+			if err == nil {
+				taken = true
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+		if !taken {
+			return filepath.Join(t.target, name), nil
+		}
+	}
+}
+
+// mkdirs creates dir and its missing parents inside the target, recording them for undo
+func (t *transaction) mkdirs(dir string) error {
+	var missing []string
+	for d := dir; d != t.target; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		d := missing[i]
+		if err := os.Mkdir(d, 0755); err != nil {
+			return err
+		}
+		t.undo = append(t.undo, func() error { return os.Remove(d) })
+	}
+
+	return nil
+}
+
+// revert undoes the moves of a failed commit and returns err
+func (t *transaction) revert(err error) error {
+	var failed []error
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		if undoErr := t.undo[i](); undoErr != nil {
+			failed = append(failed, undoErr)
+		}
+	}
+	t.undo = nil
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%w (and failed to restore the target directory: %w)", err, errors.Join(failed...))
+	}
+	return err
+}
+
+// cleanup removes the staging directory
+func (t *transaction) cleanup() error {
+	return os.RemoveAll(t.root)
+}
+
+// generateProject stages the planned files together with the lockfile and the
+// template snapshot, then commits them to fullPath
+func generateProject(ctx context.Context, fullPath string, tmpl *Template, project *Project, plan []plannedFile, strategy ConflictStrategy) (*Result, *Lockfile, error) {
+	tx, err := newTransaction(fullPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func(tx *transaction) {
+		_ = tx.cleanup()
+	}(tx)
+
+	result, err := applyPlan(ctx, tx, plan, strategy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Record how the project was generated
+	lock, err := newLockfile(project)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writeLockfile(tx.files, lock); err != nil {
+		return nil, nil, err
+	}
+	if err := writeSnapshot(tx.files, tmpl.FS); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return result, lock, nil
+}
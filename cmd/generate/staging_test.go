@@ -0,0 +1,89 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stageOver stages content for relPath in target, backing up the existing file
+func stageOver(t *testing.T, target, relPath, content string) *transaction {
+	t.Helper()
+	tx, err := newTransaction(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tx.cleanup() })
+
+	if err := tx.stage(File{Path: relPath, Content: []byte(content), Mode: 0o644}); err != nil {
+		t.Fatal(err)
+	}
+	tx.backup(relPath)
+	return tx
+}
+
+// checkFiles compares the files of dir with want, a nil content meaning the file must not exist
+func checkFiles(t *testing.T, dir string, want map[string]*string) {
+	t.Helper()
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		switch {
+		case content == nil && !errors.Is(err, os.ErrNotExist):
+			t.Errorf("%s exists, want it absent", name)
+		case content == nil:
+		case err != nil:
+			t.Errorf("%s: %v", name, err)
+		case string(got) != *content:
+			t.Errorf("%s = %q, want %q", name, got, *content)
+		}
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func TestTransactionKeepsEarlierBackups(t *testing.T) {
+	target := t.TempDir()
+	if err := os.WriteFile(filepath.Join(target, "main.go"), []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, content := range []string{"v2", "v3"} {
+		if err := stageOver(t, target, "main.go", content).commit(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkFiles(t, target, map[string]*string{
+		"main.go":        ptr("v3"),
+		"main.go.orig":   ptr("v1"),
+		"main.go.orig.1": ptr("v2"),
+		"main.go.orig.2": nil,
+	})
+}
+
+func TestTransactionRevertRestoresBackups(t *testing.T) {
+	target := t.TempDir()
+	for name, content := range map[string]string{"main.go": "v2", "main.go.orig": "v1"} {
+		if err := os.WriteFile(filepath.Join(target, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx := stageOver(t, target, "main.go", "v3")
+	if err := tx.move("main.go"); err != nil {
+		t.Fatal(err)
+	}
+	checkFiles(t, target, map[string]*string{"main.go": ptr("v3"), "main.go.orig.1": ptr("v2")})
+
+	failure := errors.New("failure")
+	if err := tx.revert(failure); !errors.Is(err, failure) {
+		t.Fatalf("revert returned %v", err)
+	}
+	checkFiles(t, target, map[string]*string{
+		"main.go":        ptr("v2"),
+		"main.go.orig":   ptr("v1"),
+		"main.go.orig.1": nil,
+	})
+}
@@ -0,0 +1,100 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/ironlabsdev/iron/internal/config"
+)
+
+// EmbeddedSource is the name of the template source compiled into the binary
+const EmbeddedSource = "embedded"
+
+// Source is a location holding one directory per template
+type Source struct {
+	// Name is EmbeddedSource or the directory the templates were loaded from
+	Name string
+	FS   fs.FS
+}
+
+// Template is a project template resolved from a Source
+type Template struct {
+	Name   string
+	Source string
+	// FS is rooted at the template directory
+	FS fs.FS
+}
+
+// Sources returns the template sources in precedence order: the given
+// directories first, then the templates embedded in the binary.
+// Directories are expanded relative to the home directory when they start with ~.
+func Sources(dirs []string) ([]Source, error) {
+	sources := make([]Source, 0, len(dirs)+1)
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		dir = config.ExpandHome(dir)
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("template directory '%s' not found: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("template directory '%s' is not a directory", dir)
+		}
+
+		sources = append(sources, Source{Name: dir, FS: os.DirFS(dir)})
+	}
+
+	embedded, err := fs.Sub(TemplatesFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded templates: %w", err)
+	}
+
+	return append(sources, Source{Name: EmbeddedSource, FS: embedded}), nil
+}
+
+// searchDirs returns explicit followed by the configured template directories.
+// Configured directories that do not exist are skipped with a warning on w, so
+// a stale entry in the config file does not break every command; a missing
+// explicit directory is left for Sources to reject.
+func searchDirs(explicit string, configured []string, w io.Writer) []string {
+	dirs := []string{explicit}
+	for _, dir := range configured {
+		if _, err := os.Stat(config.ExpandHome(dir)); errors.Is(err, os.ErrNotExist) {
+			_, _ = fmt.Fprintf(w, "%s! template directory '%s' from %s does not exist, skipping it%s\n",
+				ColorYellow, dir, config.TemplatePathsKey, ColorReset)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// ResolveTemplate returns the first template called name found in sources
+func ResolveTemplate(name string, sources []Source) (*Template, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, fmt.Errorf("invalid template name '%s'", name)
+	}
+
+	for _, source := range sources {
+		info, err := fs.Stat(source.FS, name)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+
+		templateFS, err := fs.Sub(source.FS, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open template '%s': %w", name, err)
+		}
+
+		return &Template{Name: name, Source: source.Name, FS: templateFS}, nil
+	}
+
+	return nil, fmt.Errorf("template '%s' not found", name)
+}
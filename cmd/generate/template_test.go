@@ -0,0 +1,54 @@
+package generate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSearchDirsSkipsMissingConfiguredDirs(t *testing.T) {
+	existing := t.TempDir()
+	missing := filepath.Join(existing, "missing")
+
+	var warnings bytes.Buffer
+	dirs := searchDirs("", []string{missing, existing}, &warnings)
+	if want := []string{"", existing}; !slices.Equal(dirs, want) {
+		t.Errorf("searchDirs = %q, want %q", dirs, want)
+	}
+	if !strings.Contains(warnings.String(), missing) {
+		t.Errorf("no warning about %s, got %q", missing, warnings.String())
+	}
+
+	// The embedded templates are still found
+	sources, err := Sources(dirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveTemplate("oauth", sources); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSourcesRejectsMissingExplicitDir(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	var warnings bytes.Buffer
+	dirs := searchDirs(missing, nil, &warnings)
+	if warnings.Len() > 0 {
+		t.Errorf("unexpected warning %q", warnings.String())
+	}
+	if _, err := Sources(dirs); err == nil {
+		t.Errorf("Sources(%q) succeeded, want an error for the missing --template-dir", dirs)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Sources([]string{file}); err == nil {
+		t.Errorf("Sources(%q) succeeded, want an error for a file", file)
+	}
+}
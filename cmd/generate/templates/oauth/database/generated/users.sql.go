@@ -1,3 +1,4 @@
+//go:build ignore
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
 //   sqlc v1.27.0
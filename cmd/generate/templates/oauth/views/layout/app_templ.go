@@ -1,3 +1,4 @@
+//go:build ignore
 // Code generated by templ - DO NOT EDIT.
 
 // templ: version: v0.3.898
@@ -0,0 +1,387 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ironlabsdev/iron/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeDryRun bool
+	upgradeForce  bool
+	upgradeValues []string
+)
+
+// UpgradeCmd applies template changes to an existing project
+var UpgradeCmd = &cobra.Command{
+	Use:   "upgrade [project-dir]",
+	Short: "Apply template changes to a generated project",
+	Long: `Apply the changes made to a template since a project was generated from it.
+
+The template version recorded in .iron/ and the current template are both
+rendered with the project's recorded variables, and the difference between
+them is merged into the project files with a three-way merge. Files you did
+not edit are updated directly, your edits are kept, and overlapping changes
+are left with conflict markers for you to resolve. Files last written by the
+template's post-generation hooks count as not edited.
+
+The project directory defaults to the current directory. Upgrading refuses
+to run on a git working tree with uncommitted changes unless --force is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+
+		fullPath, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve project directory: %w", err)
+		}
+
+		values, err := parseSetFlags(upgradeValues)
+		if err != nil {
+			return err
+		}
+
+		return Upgrade(fullPath, UpgradeOptions{
+			DryRun:       upgradeDryRun,
+			Force:        upgradeForce,
+			Values:       values,
+			TemplateDirs: templateDirs(),
+		})
+	},
+}
+
+func init() {
+	UpgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "Show what would change without writing files")
+	UpgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "Upgrade even when the git working tree has uncommitted changes")
+	UpgradeCmd.Flags().StringArrayVar(&upgradeValues, "set", nil, "Set a variable added to the template since generation (name=value, repeatable)")
+	UpgradeCmd.Flags().StringVar(&templateDir, "template-dir", "", "Directory to load templates from before the configured and built-in ones")
+}
+
+// UpgradeOptions configures an upgrade
+type UpgradeOptions struct {
+	DryRun bool
+	// Force skips the clean working tree check
+	Force bool
+	// Values sets variables the template declared since the project was generated
+	Values       map[string]string
+	TemplateDirs []string
+}
+
+// UpgradeResult summarises the changes made by an upgrade
+type UpgradeResult struct {
+	Updated   []string
+	Added     []string
+	Removed   []string
+	Merged    []string
+	Conflicts []string
+	// Kept lists files left alone, with the reason
+	Kept []string
+	// Rehook lists the files produced by the post-generation hooks that were
+	// replaced with template content, so the hooks need to run again
+	Rehook []string
+}
+
+// Upgrade merges the changes between the recorded and the current template into the project at fullPath
+func Upgrade(fullPath string, opts UpgradeOptions) error {
+	lock, err := ReadLockfile(fullPath)
+	if err != nil {
+		return err
+	}
+
+	if !opts.Force && !opts.DryRun {
+		if err := checkCleanWorkTree(fullPath); err != nil {
+			return err
+		}
+	}
+
+	baseTmpl, err := ReadSnapshot(fullPath, lock.Template)
+	if err != nil {
+		return err
+	}
+
+	sources, err := Sources(opts.TemplateDirs)
+	if err != nil {
+		return err
+	}
+
+	newTmpl, err := ResolveTemplate(lock.Template, sources)
+	if err != nil {
+		return err
+	}
+
+	base, err := renderLocked(baseTmpl, lock, nil)
+	if err != nil {
+		return fmt.Errorf("failed to render the recorded template: %w", err)
+	}
+
+	updated, err := renderLocked(newTmpl, lock, opts.Values)
+	if err != nil {
+		return fmt.Errorf("failed to render the current template: %w", err)
+	}
+
+	fmt.Printf("%sUpgrading %s project in '%s' (%s → %s)%s\n", ColorBlue, lock.Template, fullPath,
+		versionLabel(lock.TemplateVersion, lock.TemplateChecksum), versionLabel(updated.Manifest.Version, updated.TemplateChecksum), ColorReset)
+
+	if base.TemplateChecksum == updated.TemplateChecksum {
+		fmt.Printf("%s✓ Project is already up to date with the template%s\n", ColorGreen, ColorReset)
+		return nil
+	}
+
+	result, err := applyUpgrade(fullPath, base, updated, lock, opts.DryRun)
+	if err != nil {
+		return err
+	}
+
+	printSections(os.Stdout, []section{
+		{"Updated", "~", ColorGreen, result.Updated},
+		{"Added", "+", ColorGreen, result.Added},
+		{"Removed", "-", ColorGreen, result.Removed},
+		{"Merged with your changes", "✓", ColorGreen, result.Merged},
+		{"Conflicts (resolve the markers)", "!", ColorYellow, result.Conflicts},
+		{"Kept", "=", ColorBlue, result.Kept},
+	})
+
+	if opts.DryRun {
+		fmt.Printf("%sDry run: no files were changed%s\n", ColorBlue, ColorReset)
+		return nil
+	}
+
+	hookFiles := lock.HookFiles
+	lock, err = newLockfile(updated)
+	if err != nil {
+		return err
+	}
+	if err := lock.keepHookFiles(fullPath, hookFiles); err != nil {
+		return err
+	}
+	if err := writeLockfile(fullPath, lock); err != nil {
+		return err
+	}
+	if err := writeSnapshot(fullPath, newTmpl.FS); err != nil {
+		return err
+	}
+
+	if len(result.Rehook) > 0 {
+		var commands []string
+		for _, h := range updated.Manifest.activeHooks(updated.Data.Features) {
+			commands = append(commands, h.command())
+		}
+		fmt.Printf("%s→ The upgrade replaced the output of the post-generation hooks in %s - run them again: %s%s\n",
+			ColorBlue, strings.Join(result.Rehook, ", "), strings.Join(commands, ", "), ColorReset)
+	}
+
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("%s! %d files have conflicts - search for <<<<<<< markers and resolve them%s\n", ColorYellow, len(result.Conflicts), ColorReset)
+		return nil
+	}
+
+	fmt.Printf("%s✓ Successfully upgraded %s project%s\n", ColorGreen, lock.Template, ColorReset)
+	return nil
+}
+
+// renderLocked renders tmpl with the values recorded in the lockfile. Variables
+// and features the template no longer declares are dropped, and extra values
+// fill in variables declared since.
+func renderLocked(tmpl *Template, lock *Lockfile, extra map[string]string) (*Project, error) {
+	manifest, err := LoadManifest(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for name, value := range lock.Variables {
+		if _, ok := manifest.Variable(name); ok {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	for name, value := range extra {
+		values[name] = value
+	}
+
+	features := map[string]bool{}
+	for name, enabled := range lock.Features {
+		if _, ok := manifest.Feature(name); ok {
+			features[name] = enabled
+		}
+	}
+
+	return Render(tmpl, lock.ProjectName, Options{
+		ModulePath: lock.ModulePath,
+		Values:     values,
+		Features:   features,
+	})
+}
+
+// applyUpgrade three-way merges every file that changed between the base and
+// the updated render into the project. Files still holding the output of the
+// post-generation hooks recorded in lock count as not edited.
+func applyUpgrade(fullPath string, base, updated *Project, lock *Lockfile, dryRun bool) (*UpgradeResult, error) {
+	baseFiles, err := filesByPath(base.Files)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := filesByPath(updated.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	modes := make(map[string]fs.FileMode, len(updated.Files))
+	for _, file := range updated.Files {
+		modes[file.Path] = file.Mode
+	}
+
+	// Create-only files are left to the user once they exist
+	createOnly := map[string]bool{}
+	for _, file := range append(base.Files, updated.Files...) {
+		if file.CreateOnly {
+			createOnly[file.Path] = true
+		}
+	}
+
+	paths := make([]string, 0, len(baseFiles)+len(newFiles))
+	for p := range baseFiles {
+		paths = append(paths, p)
+	}
+	for p := range newFiles {
+		if _, ok := baseFiles[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	result := &UpgradeResult{}
+	write := func(p string, content []byte) error {
+		if dryRun {
+			return nil
+		}
+		return writeProjectFile(filepath.Join(fullPath, filepath.FromSlash(p)), File{Path: p, Content: content, Mode: modes[p]})
+	}
+
+	for _, p := range paths {
+		baseContent, inBase := baseFiles[p]
+		newContent, inNew := newFiles[p]
+
+		// The template did not change this file
+		if inBase && inNew && bytes.Equal(baseContent, newContent) {
+			continue
+		}
+
+		ours, err := os.ReadFile(filepath.Join(fullPath, filepath.FromSlash(p)))
+		exists := err == nil
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		hookOwned := exists && lock.hookOwned(p, ours)
+		unedited := (inBase && bytes.Equal(ours, baseContent)) || hookOwned
+
+		switch {
+		case exists && createOnly[p]:
+		case !exists && !inNew:
+			// Removed from the template and already gone
+		case !exists && inBase:
+			result.Kept = append(result.Kept, p+" (deleted by you, changed in the template)")
+		case !exists:
+			if err := write(p, newContent); err != nil {
+				return nil, err
+			}
+			result.Added = append(result.Added, p)
+		case !inNew:
+			if !unedited {
+				result.Kept = append(result.Kept, p+" (removed from the template, edited by you)")
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(filepath.Join(fullPath, filepath.FromSlash(p))); err != nil {
+					return nil, fmt.Errorf("failed to remove %s: %w", p, err)
+				}
+			}
+			result.Removed = append(result.Removed, p)
+		case bytes.Equal(ours, newContent):
+			// Already matches the new template
+		case unedited:
+			if err := write(p, newContent); err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, p)
+			if hookOwned {
+				result.Rehook = append(result.Rehook, p)
+			}
+		case isBinary(ours) || isBinary(newContent) || (inBase && isBinary(baseContent)):
+			result.Kept = append(result.Kept, p+" (binary file changed by you and the template)")
+		default:
+			merged, conflict := diff.Merge3(string(baseContent), string(ours), string(newContent), diff.MergeLabels{
+				Ours:   "yours",
+				Theirs: "template " + versionLabel(updated.Manifest.Version, updated.TemplateChecksum),
+			})
+			if err := write(p, []byte(merged)); err != nil {
+				return nil, err
+			}
+			if conflict {
+				result.Conflicts = append(result.Conflicts, p)
+			} else {
+				result.Merged = append(result.Merged, p)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// checkCleanWorkTree refuses to continue when dir is a git working tree with uncommitted changes
+func checkCleanWorkTree(dir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil
+	}
+
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		// Not a git repository, there is nothing to check
+		return nil
+	}
+
+	if len(bytes.TrimSpace(out)) > 0 {
+		return fmt.Errorf("'%s' has uncommitted changes - commit or stash them first, or use --force", dir)
+	}
+
+	return nil
+}
+
+// filesByPath indexes the content of rendered files by path
+func filesByPath(files []File) (map[string][]byte, error) {
+	byPath := make(map[string][]byte, len(files))
+	for _, file := range files {
+		content, err := file.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		byPath[file.Path] = content
+	}
+	return byPath, nil
+}
+
+// versionLabel identifies a template version, falling back to a short checksum
+func versionLabel(version, sum string) string {
+	short := strings.TrimPrefix(sum, "sha256:")
+	if len(short) > 8 {
+		short = short[:8]
+	}
+
+	if version == "" {
+		return short
+	}
+	return version + " (" + short + ")"
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -52,6 +53,10 @@ func init() {
 
 	// Add subcommands
 	rootCmd.AddCommand(generate.GenerateCmd)
+	rootCmd.AddCommand(generate.UpgradeCmd)
+	rootCmd.AddCommand(generate.DiffCmd)
+	rootCmd.AddCommand(generate.TemplateCmd)
+	rootCmd.AddCommand(generate.RoutesCmd)
 	rootCmd.AddCommand(versionCmd)
 
 	// Version command flags
@@ -105,4 +110,13 @@ func initConfig() {
 	}
 
 	viper.AutomaticEnv() // read in environment variables that match
+
+	// If a config file is found, read it in
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			_, _ = fmt.Fprintf(os.Stderr, "%sError reading config file: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+	}
 }
@@ -3,3 +3,9 @@ package config
 const (
 	Directory = ".iron"
 )
+
+// Configuration keys
+const (
+	// TemplatePathsKey lists directories searched for templates before the embedded ones
+	TemplatePathsKey = "templates.paths"
+)
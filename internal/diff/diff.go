@@ -0,0 +1,207 @@
+// Package diff computes line-based differences and three-way merges of text files.
+package diff
+
+import "strings"
+
+// Op is the kind of an edit
+type Op int
+
+const (
+	// Equal lines are present in both inputs
+	Equal Op = iota
+	// Delete lines are only present in the first input
+	Delete
+	// Insert lines are only present in the second input
+	Insert
+)
+
+// Edit is a single line of an edit script
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// Change replaces the lines A[Start:End] of the first input with Lines
+type Change struct {
+	Start int
+	End   int
+	Lines []string
+}
+
+// SplitLines splits text into lines, keeping the line terminators so that
+// joining the lines gives back the original text
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns the shortest edit script turning a into b, using the linear
+// space variant of Myers' algorithm
+func Lines(a, b []string) []Edit {
+	return appendEdits(make([]Edit, 0, max(len(a), len(b))), a, b)
+}
+
+// appendEdits appends the edit script turning a into b. Common leading and
+// trailing lines are matched directly, the rest is split in two at a point on
+// an optimal path and each half is compared in turn.
+func appendEdits(edits []Edit, a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	edits = appendLines(edits, Equal, a[:prefix])
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	x, y := -1, -1
+	if len(a) > 0 && len(b) > 0 {
+		x, y = split(a, b)
+	}
+	if x < 0 || (x == 0 && y == 0) || (x == len(a) && y == len(b)) {
+		// Nothing in common: replace every line
+		edits = appendLines(edits, Delete, a)
+		edits = appendLines(edits, Insert, b)
+	} else {
+		edits = appendEdits(edits, a[:x], b[:y])
+		edits = appendEdits(edits, a[x:], b[y:])
+	}
+
+	return appendLines(edits, Equal, common)
+}
+
+// split returns a point (x, y) where an optimal path from the start to the end
+// of a and b crosses, found by extending the furthest reaching paths from both
+// ends until they overlap. Only the current frontiers are kept, so memory is
+// linear in len(a)+len(b). It returns -1, -1 when a and b have no line in common.
+func split(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[k+offset] holds the furthest x reached on diagonal k from the start,
+	// backward[k+offset] the furthest distance from the end on diagonal k of the reversed inputs
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// With an odd delta the paths meet while extending the forward path
+	odd := delta%2 != 0
+	// Diagonals leaving the grid are skipped from then on
+	var kStart, kEnd, rStart, rEnd int
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + kStart; k <= d-kEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			switch {
+			case x > n:
+				kEnd += 2
+			case y > m:
+				kStart += 2
+			case odd:
+				if r := offset + delta - k; r >= 0 && r < len(backward) && backward[r] != -1 && x >= n-backward[r] {
+					return x, y
+				}
+			}
+		}
+
+		for k := -d + rStart; k <= d-rEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			switch {
+			case x > n:
+				rEnd += 2
+			case y > m:
+				rStart += 2
+			case !odd:
+				if f := offset + delta - k; f >= 0 && f < len(forward) && forward[f] != -1 && forward[f] >= n-x {
+					return forward[f], forward[f] - (delta - k)
+				}
+			}
+		}
+	}
+
+	return -1, -1
+}
+
+// appendLines appends an edit with the given op for every line
+func appendLines(edits []Edit, op Op, lines []string) []Edit {
+	for _, line := range lines {
+		edits = append(edits, Edit{Op: op, Line: line})
+	}
+	return edits
+}
+
+// Changes groups an edit script into the changes it makes to the first input
+func Changes(edits []Edit) []Change {
+	var (
+		changes []Change
+		current *Change
+		pos     int
+	)
+
+	for _, e := range edits {
+		switch e.Op {
+		case Equal:
+			if current != nil {
+				changes = append(changes, *current)
+				current = nil
+			}
+			pos++
+		case Delete:
+			if current == nil {
+				current = &Change{Start: pos, End: pos}
+			}
+			pos++
+			current.End = pos
+		case Insert:
+			if current == nil {
+				current = &Change{Start: pos, End: pos}
+			}
+			current.Lines = append(current.Lines, e.Line)
+		}
+	}
+
+	if current != nil {
+		changes = append(changes, *current)
+	}
+
+	return changes
+}
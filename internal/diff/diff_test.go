@@ -0,0 +1,170 @@
+package diff
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with the golden file, or rewrites it with -update
+func golden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// readCase reads the named files of a testdata case directory
+func readCase(t *testing.T, dir string, names ...string) []string {
+	t.Helper()
+	contents := make([]string, len(names))
+	for i, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[i] = string(content)
+	}
+	return contents
+}
+
+func caseDirs(t *testing.T, pattern string) []string {
+	t.Helper()
+	dirs, err := filepath.Glob(pattern)
+	if err != nil || len(dirs) == 0 {
+		t.Fatalf("no test cases in %s: %v", pattern, err)
+	}
+	return dirs
+}
+
+func TestUnified(t *testing.T) {
+	for _, dir := range caseDirs(t, "testdata/unified/*") {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			files := readCase(t, dir, "a", "b")
+			a, b := SplitLines(files[0]), SplitLines(files[1])
+
+			edits := Lines(a, b)
+			checkEdits(t, a, b, edits)
+			golden(t, filepath.Join(dir, "want.diff"), Unified("a/file", "b/file", edits, 3))
+		})
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	for _, dir := range caseDirs(t, "testdata/merge/*") {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			files := readCase(t, dir, "base", "ours", "theirs")
+
+			merged, conflict := Merge3(files[0], files[1], files[2], MergeLabels{Ours: "ours", Theirs: "theirs"})
+			// Cases expecting conflicts have conflict in their name
+			if want := strings.Contains(filepath.Base(dir), "conflict"); conflict != want {
+				t.Errorf("conflict = %v, want %v", conflict, want)
+			}
+			golden(t, filepath.Join(dir, "want"), merged)
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	for _, text := range []string{"", "a", "a\n", "a\nb", "a\nb\n", "\n\n"} {
+		if got := strings.Join(SplitLines(text), ""); got != text {
+			t.Errorf("SplitLines(%q) joins to %q", text, got)
+		}
+	}
+}
+
+// TestLinesShortest compares the length of random edit scripts with the
+// longest common subsequence of their inputs
+func TestLinesShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 2000; i++ {
+		a, b := randomLines(), randomLines()
+		edits := Lines(a, b)
+		checkEdits(t, a, b, edits)
+
+		insertions, deletions := Count(edits)
+		if want := len(a) + len(b) - 2*lcs(a, b); insertions+deletions != want {
+			t.Fatalf("Lines(%q, %q) has %d edits, want %d", a, b, insertions+deletions, want)
+		}
+	}
+}
+
+// TestLinesLarge diffs a rewritten file of 10000 lines, which must not need
+// memory quadratic in the number of changes
+func TestLinesLarge(t *testing.T) {
+	a := make([]string, 10000)
+	b := make([]string, 10000)
+	for i := range a {
+		a[i] = fmt.Sprintf("old %d\n", i)
+		b[i] = fmt.Sprintf("new %d\n", i)
+		if i%10 == 0 {
+			b[i] = a[i]
+		}
+	}
+
+	edits := Lines(a, b)
+	checkEdits(t, a, b, edits)
+	if insertions, deletions := Count(edits); insertions != 9000 || deletions != 9000 {
+		t.Errorf("got %d insertions and %d deletions, want 9000 each", insertions, deletions)
+	}
+}
+
+// checkEdits verifies that the edit script turns a into b
+func checkEdits(t *testing.T, a, b []string, edits []Edit) {
+	t.Helper()
+	var gotA, gotB []string
+	for _, e := range edits {
+		if e.Op != Insert {
+			gotA = append(gotA, e.Line)
+		}
+		if e.Op != Delete {
+			gotB = append(gotB, e.Line)
+		}
+	}
+	if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+		t.Fatalf("edit script of %q -> %q gives %q -> %q", a, b, gotA, gotB)
+	}
+}
+
+// lcs returns the length of the longest common subsequence of a and b
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
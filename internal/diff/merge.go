@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MergeLabels names the sides of a conflict in the conflict markers
+type MergeLabels struct {
+	Ours   string
+	Theirs string
+}
+
+// Merge3 merges the changes made to base in ours and in theirs. Changes that
+// touch the same lines of base are conflicts, written out between git-style
+// conflict markers around the lines where ours and theirs differ. It reports
+// whether any conflict was found. With an empty base it is a two-way merge.
+func Merge3(base, ours, theirs string, labels MergeLabels) (string, bool) {
+	baseLines := SplitLines(base)
+	oursChanges := Changes(Lines(baseLines, SplitLines(ours)))
+	theirsChanges := Changes(Lines(baseLines, SplitLines(theirs)))
+
+	var (
+		out      strings.Builder
+		conflict bool
+		pos      int
+		i, j     int
+	)
+
+	for i < len(oursChanges) || j < len(theirsChanges) {
+		// Start a cluster with whichever change comes first in base
+		start := nextStart(oursChanges, i, theirsChanges, j)
+		end := start
+		oi, tj := i, j
+
+		// Grow the cluster while changes from either side overlap or touch it
+		for {
+			grew := false
+			for oi < len(oursChanges) && oursChanges[oi].Start <= end {
+				end = max(end, oursChanges[oi].End)
+				oi++
+				grew = true
+			}
+			for tj < len(theirsChanges) && theirsChanges[tj].Start <= end {
+				end = max(end, theirsChanges[tj].End)
+				tj++
+				grew = true
+			}
+			if !grew {
+				break
+			}
+		}
+
+		writeLines(&out, baseLines[pos:start])
+
+		oursLines := apply(baseLines, start, end, oursChanges[i:oi])
+		theirsLines := apply(baseLines, start, end, theirsChanges[j:tj])
+
+		switch {
+		case oi == i:
+			writeLines(&out, theirsLines)
+		case tj == j:
+			writeLines(&out, oursLines)
+		case slices.Equal(oursLines, theirsLines):
+			writeLines(&out, oursLines)
+		default:
+			conflict = true
+			writeConflict(&out, oursLines, theirsLines, labels)
+		}
+
+		pos = end
+		i, j = oi, tj
+	}
+
+	writeLines(&out, baseLines[pos:])
+
+	return out.String(), conflict
+}
+
+// writeConflict writes the two versions of a conflicting region. Lines they
+// share are written once and only the runs that differ get conflict markers.
+// Like git, runs separated only by lines without letters or digits, such as
+// blank lines and braces, stay in one conflict.
+func writeConflict(out *strings.Builder, ours, theirs []string, labels MergeLabels) {
+	var (
+		oursRun, theirsRun []string
+		common             []string
+	)
+
+	flush := func() {
+		if len(oursRun) > 0 || len(theirsRun) > 0 {
+			out.WriteString("<<<<<<< " + labels.Ours + "\n")
+			writeLines(out, terminate(oursRun))
+			out.WriteString("=======\n")
+			writeLines(out, terminate(theirsRun))
+			out.WriteString(">>>>>>> " + labels.Theirs + "\n")
+		}
+		writeLines(out, common)
+		oursRun, theirsRun, common = nil, nil, nil
+	}
+
+	for _, e := range Lines(ours, theirs) {
+		if e.Op == Equal {
+			common = append(common, e.Line)
+			continue
+		}
+
+		if len(common) > 0 {
+			if len(oursRun) == 0 && len(theirsRun) == 0 || hasAlnum(common) {
+				flush()
+			} else {
+				oursRun = append(oursRun, common...)
+				theirsRun = append(theirsRun, common...)
+				common = nil
+			}
+		}
+		if e.Op == Delete {
+			oursRun = append(oursRun, e.Line)
+		} else {
+			theirsRun = append(theirsRun, e.Line)
+		}
+	}
+	flush()
+}
+
+// hasAlnum reports whether any of lines holds a letter or a digit
+func hasAlnum(lines []string) bool {
+	for _, line := range lines {
+		if strings.IndexFunc(line, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nextStart returns the base position of the earliest pending change
+func nextStart(a []Change, i int, b []Change, j int) int {
+	switch {
+	case i >= len(a):
+		return b[j].Start
+	case j >= len(b):
+		return a[i].Start
+	}
+	return min(a[i].Start, b[j].Start)
+}
+
+// apply returns base[start:end] with the given changes applied
+func apply(base []string, start, end int, changes []Change) []string {
+	var lines []string
+	pos := start
+
+	for _, c := range changes {
+		lines = append(lines, base[pos:c.Start]...)
+		lines = append(lines, c.Lines...)
+		pos = c.End
+	}
+
+	return append(lines, base[pos:end]...)
+}
+
+// terminate makes sure the last line ends with a newline so markers start on their own line
+func terminate(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+
+	terminated := append([]string(nil), lines...)
+	terminated[len(terminated)-1] += "\n"
+	return terminated
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
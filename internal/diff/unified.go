@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Count returns the number of inserted and deleted lines in an edit script
+func Count(edits []Edit) (insertions, deletions int) {
+	for _, e := range edits {
+		switch e.Op {
+		case Insert:
+			insertions++
+		case Delete:
+			deletions++
+		}
+	}
+	return insertions, deletions
+}
+
+// Unified formats an edit script as a unified diff with the given number of
+// context lines around each change. It returns an empty string when the
+// edit script has no changes.
+func Unified(from, to string, edits []Edit, context int) string {
+	// Line numbers in a and b before each edit
+	aLine := make([]int, len(edits)+1)
+	bLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if e.Op != Insert {
+			aLine[i+1]++
+		}
+		if e.Op != Delete {
+			bLine[i+1]++
+		}
+	}
+
+	var out strings.Builder
+
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Equal {
+			i++
+			continue
+		}
+
+		start := max(0, i-context)
+
+		// Extend the hunk while the next change is close enough to share context
+		end := i
+		for {
+			for end < len(edits) && edits[end].Op != Equal {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].Op == Equal {
+				next++
+			}
+			if next == len(edits) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		end = min(len(edits), end+context)
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(aLine[start], aLine[end]-aLine[start]),
+			hunkRange(bLine[start], bLine[end]-bLine[start]))
+
+		for _, e := range edits[start:end] {
+			switch e.Op {
+			case Equal:
+				out.WriteString(" ")
+			case Delete:
+				out.WriteString("-")
+			case Insert:
+				out.WriteString("+")
+			}
+			out.WriteString(e.Line)
+			if !strings.HasSuffix(e.Line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+
+	return out.String()
+}
+
+// hunkRange formats the start line and length of one side of a hunk
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,353 @@
+package routes
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// DeclareHandler appends name := constructor(<recv>.<field>, ...) to
+// RegisterRoutes, separated from the previous statements by a blank line.
+// fields are the Controller fields passed to the constructor, e.g. Logger.
+func (r *Router) DeclareHandler(name, constructor string, fields ...string) error {
+	if _, err := parser.ParseExpr(constructor); err != nil {
+		return fmt.Errorf("invalid handler constructor '%s': %w", constructor, err)
+	}
+
+	recv := receiver(r.routes)
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		args[i] = recv + "." + field
+	}
+
+	stmt := fmt.Sprintf("%s := %s(%s)", name, constructor, strings.Join(args, ", "))
+	return r.insert(RoutesFunc, len(r.routes.Body.List), stmt, true)
+}
+
+// Add registers a route wrapped with requestlog.NewHandler, after the last
+// statement using the same handler variable or at the end of RegisterRoutes.
+// AnyMethod routes are registered with Router.Handle. It fails when a route
+// with the same method and path is already registered.
+func (r *Router) Add(route Route) error {
+	methodConst, ok := methodConsts[route.Method]
+	if !ok && route.Method != AnyMethod {
+		return fmt.Errorf("unknown HTTP method '%s'", route.Method)
+	}
+
+	for _, existing := range r.Routes() {
+		if existing.Path == route.Path && (existing.Method == route.Method || existing.Method == AnyMethod || route.Method == AnyMethod) {
+			return fmt.Errorf("route %s is already registered to %s", route, existing.Handler)
+		}
+	}
+
+	handler, err := parser.ParseExpr(route.Handler)
+	if err != nil {
+		return fmt.Errorf("invalid route handler '%s': %w", route.Handler, err)
+	}
+	handlerVar, _, _ := strings.Cut(types.ExprString(handler), ".")
+	if !slices.Contains(r.HandlerVars(), handlerVar) && !r.imports(handlerVar) {
+		return fmt.Errorf("unknown handler '%s' - %s declares %s", route.Handler, RoutesFunc, strings.Join(r.HandlerVars(), ", "))
+	}
+
+	recv := receiver(r.routes)
+	stmt := fmt.Sprintf("%s.Router.Method(http.%s, %s, requestlog.NewHandler(%s, %s.Logger))",
+		recv, methodConst, strconv.Quote(route.Path), types.ExprString(handler), recv)
+	if route.Method == AnyMethod {
+		stmt = fmt.Sprintf("%s.Router.Handle(%s, requestlog.NewHandler(%s, %s.Logger))",
+			recv, strconv.Quote(route.Path), types.ExprString(handler), recv)
+	}
+
+	at := len(r.routes.Body.List)
+	for i, stmt := range r.routes.Body.List {
+		if usesIdent(stmt, handlerVar) {
+			at = i + 1
+		}
+	}
+
+	return r.insert(RoutesFunc, at, stmt, false)
+}
+
+// Remove unregisters the route with the given method and path. Handler
+// variables and imports only used by that route are removed with it.
+func (r *Router) Remove(method, routePath string) (Route, error) {
+	for _, route := range r.Routes() {
+		if route.Method == method && route.Path == routePath {
+			return route, r.removeStmt(RoutesFunc, route.stmt)
+		}
+	}
+	return Route{}, fmt.Errorf("no route %s %s is registered in %s", method, routePath, RoutesFunc)
+}
+
+// Use registers middleware after the last Router.Use call of RegisterUses
+func (r *Router) Use(middleware string) error {
+	if r.uses == nil {
+		return fmt.Errorf("no %s.%s method found in %s", ControllerType, UsesFunc, r.Filename)
+	}
+
+	expr, err := parser.ParseExpr(middleware)
+	if err != nil {
+		return fmt.Errorf("invalid middleware '%s': %w", middleware, err)
+	}
+	middleware = types.ExprString(expr)
+
+	at := len(r.uses.Body.List)
+	for _, existing := range r.Middleware() {
+		if existing.Expr == middleware {
+			return fmt.Errorf("middleware %s is already registered", middleware)
+		}
+		at = slices.Index(r.uses.Body.List, existing.stmt) + 1
+	}
+
+	return r.insert(UsesFunc, at, fmt.Sprintf("%s.Router.Use(%s)", receiver(r.uses), middleware), false)
+}
+
+// RemoveUse unregisters middleware. Imports only used by it are removed with it.
+func (r *Router) RemoveUse(middleware string) error {
+	if expr, err := parser.ParseExpr(middleware); err == nil {
+		middleware = types.ExprString(expr)
+	}
+
+	for _, existing := range r.Middleware() {
+		if existing.Expr != middleware {
+			continue
+		}
+		if existing.shared {
+			return fmt.Errorf("middleware %s is registered together with other middleware on line %d - remove it by hand", middleware, existing.Line)
+		}
+		return r.removeStmt(UsesFunc, existing.stmt)
+	}
+	return fmt.Errorf("middleware %s is not registered in %s", middleware, UsesFunc)
+}
+
+// AddImport imports importPath in the router file unless it already is
+func (r *Router) AddImport(importPath string) error {
+	if !astutil.AddImport(r.fset, r.file, importPath) {
+		return nil
+	}
+	return r.reprint()
+}
+
+// Bytes formats the router file
+func (r *Router) Bytes() ([]byte, error) {
+	src, err := format.Source(r.src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s: %w", r.Filename, err)
+	}
+	return src, nil
+}
+
+// method returns RegisterRoutes or RegisterUses
+func (r *Router) method(name string) *ast.FuncDecl {
+	if name == UsesFunc {
+		return r.uses
+	}
+	return r.routes
+}
+
+// insert adds stmt to the method so it becomes its statement at index at,
+// on its own line after the previous statement and its trailing comment.
+// Bytes lays the statement out as go fmt would.
+func (r *Router) insert(method string, at int, stmt string, blankLine bool) error {
+	body := r.method(method).Body
+
+	offset := r.offset(body.Lbrace) + 1
+	text := "\n\t" + stmt
+	if at > 0 {
+		offset = r.lineEnd(r.offset(body.List[at-1].End()))
+		if offset > r.offset(body.Rbrace) {
+			offset = r.offset(body.Rbrace)
+		}
+		if blankLine {
+			text = "\n" + text
+		}
+	}
+
+	return r.splice(offset, offset, text)
+}
+
+// removeStmt removes the lines of a statement of the method along with the
+// comment above it, then the variables and imports it was the last user of
+func (r *Router) removeStmt(method string, stmt ast.Stmt) error {
+	var names []string
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && !slices.Contains(names, ident.Name) {
+			names = append(names, ident.Name)
+		}
+		return true
+	})
+
+	start := r.lineStart(r.offset(stmt.Pos()))
+	for _, group := range r.file.Comments {
+		commentStart := r.lineStart(r.offset(group.Pos()))
+		if group.Pos() > r.method(method).Body.Lbrace && r.lineEnd(r.offset(group.End()))+1 == start &&
+			len(bytes.TrimSpace(r.src[commentStart:r.offset(group.Pos())])) == 0 {
+			start = commentStart
+		}
+	}
+	end := min(r.lineEnd(r.offset(stmt.End()))+1, len(r.src))
+
+	// Keep a single blank line between the remaining groups of statements
+	before := strings.TrimSpace(string(r.src[r.lineStart(max(start-1, 0)):start]))
+	after := strings.TrimSpace(string(r.src[end:min(r.lineEnd(end)+1, len(r.src))]))
+	switch {
+	case after == "" && (before == "" || strings.HasSuffix(before, "{")):
+		end = min(r.lineEnd(end)+1, len(r.src))
+	case before == "" && strings.HasPrefix(after, "}"):
+		start = r.lineStart(start - 1)
+	}
+
+	if err := r.splice(start, end, ""); err != nil {
+		return err
+	}
+
+	// Variables declared in the method that are no longer used
+	for _, name := range names {
+		for _, decl := range r.method(method).Body.List {
+			if declares(decl, name) && !r.usedBesides(method, decl, name) {
+				if err := r.removeStmt(method, decl); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	return r.pruneImports(names)
+}
+
+// declares reports whether stmt is name := ...
+func declares(stmt ast.Stmt, name string) bool {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 1 {
+		return false
+	}
+	ident, ok := assign.Lhs[0].(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// usedBesides reports whether a statement of the method other than decl refers to name
+func (r *Router) usedBesides(method string, decl ast.Stmt, name string) bool {
+	for _, stmt := range r.method(method).Body.List {
+		if stmt != decl && usesIdent(stmt, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneImports removes the imports named in names that the file no longer uses
+func (r *Router) pruneImports(names []string) error {
+	pruned := false
+	for _, spec := range slices.Clone(r.file.Imports) {
+		name := importName(spec)
+		if !slices.Contains(names, name) || r.usesPackage(name) {
+			continue
+		}
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		var alias string
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		pruned = astutil.DeleteNamedImport(r.fset, r.file, alias, importPath) || pruned
+	}
+
+	if !pruned {
+		return nil
+	}
+	return r.reprint()
+}
+
+// imports reports whether the file imports a package called name
+func (r *Router) imports(name string) bool {
+	for _, spec := range r.file.Imports {
+		if importName(spec) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// importName is the name an import is referred to by, assuming the package
+// is named after the last element of its path without a major version suffix
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	name := path.Base(importPath)
+	if strings.HasPrefix(name, "v") && strings.Trim(name[1:], "0123456789") == "" && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	return name
+}
+
+// usesPackage reports whether the declarations of the file refer to the package name
+func (r *Router) usesPackage(name string) bool {
+	used := false
+	for _, decl := range r.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name {
+					used = true
+				}
+			}
+			return !used
+		})
+	}
+	return used
+}
+
+// splice replaces src[start:end] with text and parses the result again
+func (r *Router) splice(start, end int, text string) error {
+	src := slices.Concat(r.src[:start], []byte(text), r.src[end:])
+	return r.reparse(src)
+}
+
+// reprint prints the edited syntax tree and parses the result again
+func (r *Router) reprint() error {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, r.fset, r.file); err != nil {
+		return fmt.Errorf("failed to format %s: %w", r.Filename, err)
+	}
+	return r.reparse(buf.Bytes())
+}
+
+func (r *Router) reparse(src []byte) error {
+	parsed, err := Parse(r.Filename, src)
+	if err != nil {
+		return fmt.Errorf("failed to edit %s: %w", r.Filename, err)
+	}
+	*r = *parsed
+	return nil
+}
+
+// offset returns the byte offset of pos in the source
+func (r *Router) offset(pos token.Pos) int {
+	return r.fset.Position(pos).Offset
+}
+
+// lineStart returns the offset of the start of the line holding offset
+func (r *Router) lineStart(offset int) int {
+	return bytes.LastIndexByte(r.src[:offset], '\n') + 1
+}
+
+// lineEnd returns the offset of the newline ending the line holding offset
+func (r *Router) lineEnd(offset int) int {
+	if i := bytes.IndexByte(r.src[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+	return len(r.src)
+}
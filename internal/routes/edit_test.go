@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"strings"
+	"testing"
+)
+
+const routerSrc = `package router
+
+import (
+	"net/http"
+
+	"example.com/app/web/requestlog"
+)
+
+func (c *Controller) RegisterRoutes() {
+	pageHandler := pages.NewPages(c.Logger)
+	c.Router.Method(http.MethodGet, "/", requestlog.NewHandler(pageHandler.Home, c.Logger))
+}
+`
+
+func TestAddAnyMethod(t *testing.T) {
+	r, err := Parse("router.go", []byte(routerSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Add(Route{Method: AnyMethod, Path: "/webhook", Handler: "pageHandler.Webhook"}); err != nil {
+		t.Fatal(err)
+	}
+	src, err := r.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `c.Router.Handle("/webhook", requestlog.NewHandler(pageHandler.Webhook, c.Logger))`; !strings.Contains(string(src), want) {
+		t.Errorf("router does not contain %s:\n%s", want, src)
+	}
+
+	// Any route on the same path conflicts with it
+	for _, method := range []string{AnyMethod, "GET"} {
+		if err := r.Add(Route{Method: method, Path: "/webhook", Handler: "pageHandler.Home"}); err == nil {
+			t.Errorf("adding %s /webhook succeeded, want a conflict", method)
+		}
+	}
+	if err := r.Add(Route{Method: AnyMethod, Path: "/", Handler: "pageHandler.Home"}); err == nil {
+		t.Error("adding ANY / succeeded, want a conflict with GET /")
+	}
+
+	route, err := r.Remove(AnyMethod, "/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route.Handler != "pageHandler.Webhook" {
+		t.Errorf("removed the route handled by %s", route.Handler)
+	}
+	if src, _ := r.Bytes(); string(src) != routerSrc {
+		t.Errorf("removing the route did not restore the router:\n%s", src)
+	}
+}
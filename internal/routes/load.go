@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Load finds the file declaring Controller.RegisterRoutes among the packages
+// of the Go module in dir and parses it
+func Load(dir string) (*Router, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the packages in '%s': %w", dir, err)
+	}
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			if controllerMethod(file, RoutesFunc) == nil {
+				continue
+			}
+
+			filename := pkg.Fset.Position(file.Pos()).Filename
+			src, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+			}
+			return Parse(filename, src)
+		}
+	}
+
+	return nil, fmt.Errorf("no %s.%s method found in the packages of '%s'", ControllerType, RoutesFunc, dir)
+}
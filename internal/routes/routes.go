@@ -0,0 +1,305 @@
+// Package routes reads and edits the RegisterRoutes and RegisterUses methods
+// of the router Controller of generated projects. Statements are located
+// through go/ast and edited in place in the source, so the comments and
+// layout of the rest of the file are kept.
+package routes
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Methods of the router controller
+const (
+	ControllerType = "Controller"
+	// RoutesFunc registers the routes with Router.Method
+	RoutesFunc = "RegisterRoutes"
+	// UsesFunc registers the middleware with Router.Use
+	UsesFunc = "RegisterUses"
+)
+
+// AnyMethod is the method of routes registered with Router.Handle, which match every method
+const AnyMethod = "ANY"
+
+// methodConsts maps HTTP methods to their net/http constant
+var methodConsts = map[string]string{
+	http.MethodGet:     "MethodGet",
+	http.MethodHead:    "MethodHead",
+	http.MethodPost:    "MethodPost",
+	http.MethodPut:     "MethodPut",
+	http.MethodPatch:   "MethodPatch",
+	http.MethodDelete:  "MethodDelete",
+	http.MethodConnect: "MethodConnect",
+	http.MethodOptions: "MethodOptions",
+	http.MethodTrace:   "MethodTrace",
+}
+
+// Route is a route registered in RegisterRoutes
+type Route struct {
+	// Method is the upper-case HTTP method, e.g. GET, or AnyMethod
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Handler is the handler expression without the request logging wrapper,
+	// e.g. pageHandler.Login
+	Handler string `json:"handler"`
+	Line    int    `json:"line,omitempty"`
+
+	stmt ast.Stmt
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Middleware is a middleware registered with Router.Use in RegisterUses
+type Middleware struct {
+	// Expr is the middleware expression, e.g. middleware.Logger
+	Expr string `json:"middleware"`
+	Line int    `json:"line,omitempty"`
+
+	stmt ast.Stmt
+	// shared is set when the Use call registers other middleware too
+	shared bool
+}
+
+// ValidMethod reports whether method is an upper-case HTTP method
+func ValidMethod(method string) bool {
+	_, ok := methodConsts[method]
+	return ok
+}
+
+// Router is a parsed file declaring the Controller methods
+type Router struct {
+	// Filename is the path the file was read from
+	Filename string
+
+	src  []byte
+	fset *token.FileSet
+	file *ast.File
+	// routes is RegisterRoutes and uses is RegisterUses, nil when the file does not declare it
+	routes *ast.FuncDecl
+	uses   *ast.FuncDecl
+}
+
+// Parse parses a router file declaring Controller.RegisterRoutes
+func Parse(filename string, src []byte) (*Router, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	r := &Router{
+		Filename: filename,
+		src:      src,
+		fset:     fset,
+		file:     file,
+		routes:   controllerMethod(file, RoutesFunc),
+		uses:     controllerMethod(file, UsesFunc),
+	}
+	if r.routes == nil {
+		return nil, fmt.Errorf("no %s.%s method found in %s", ControllerType, RoutesFunc, filename)
+	}
+	for _, fn := range []*ast.FuncDecl{r.routes, r.uses} {
+		if fn != nil && receiver(fn) == "" {
+			return nil, fmt.Errorf("%s in %s must have a named receiver", fn.Name.Name, filename)
+		}
+	}
+	return r, nil
+}
+
+// controllerMethod finds the method name of Controller or *Controller in file
+func controllerMethod(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != name || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if ident, ok := recv.(*ast.Ident); ok && ident.Name == ControllerType {
+			return fn
+		}
+	}
+	return nil
+}
+
+// receiver returns the receiver name of a method, e.g. c
+func receiver(fn *ast.FuncDecl) string {
+	if names := fn.Recv.List[0].Names; len(names) == 1 && names[0].Name != "_" {
+		return names[0].Name
+	}
+	return ""
+}
+
+// Routes lists the routes registered in RegisterRoutes, in order
+func (r *Router) Routes() []Route {
+	var routes []Route
+	for _, stmt := range r.routes.Body.List {
+		if route, ok := r.route(stmt); ok {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// route decodes a <recv>.Router.Method(method, path, handler) statement, as
+// well as the Handle, HandleFunc and Get, Post... shortcuts of chi
+func (r *Router) route(stmt ast.Stmt) (Route, bool) {
+	call, fn, ok := r.routerCall(stmt, receiver(r.routes))
+	if !ok {
+		return Route{}, false
+	}
+
+	var method string
+	args := call.Args
+	switch {
+	case fn == "Method" && len(args) == 3:
+		if method, ok = methodName(args[0]); !ok {
+			return Route{}, false
+		}
+		args = args[1:]
+	case (fn == "Handle" || fn == "HandleFunc") && len(args) == 2:
+		method = AnyMethod
+	case ValidMethod(strings.ToUpper(fn)) && len(args) == 2:
+		method = strings.ToUpper(fn)
+	default:
+		return Route{}, false
+	}
+
+	lit, ok := args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return Route{}, false
+	}
+	path, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return Route{}, false
+	}
+
+	// Unwrap requestlog.NewHandler(handler, logger)
+	handler := args[1]
+	if wrapped, ok := handler.(*ast.CallExpr); ok && types.ExprString(wrapped.Fun) == "requestlog.NewHandler" && len(wrapped.Args) == 2 {
+		handler = wrapped.Args[0]
+	}
+
+	return Route{
+		Method:  method,
+		Path:    path,
+		Handler: types.ExprString(handler),
+		Line:    r.fset.Position(stmt.Pos()).Line,
+		stmt:    stmt,
+	}, true
+}
+
+// Middleware lists the middleware registered in RegisterUses, in order.
+// It is empty when the file does not declare RegisterUses.
+func (r *Router) Middleware() []Middleware {
+	if r.uses == nil {
+		return nil
+	}
+
+	var middleware []Middleware
+	for _, stmt := range r.uses.Body.List {
+		call, fn, ok := r.routerCall(stmt, receiver(r.uses))
+		if !ok || fn != "Use" {
+			continue
+		}
+		for _, arg := range call.Args {
+			middleware = append(middleware, Middleware{
+				Expr:   types.ExprString(arg),
+				Line:   r.fset.Position(stmt.Pos()).Line,
+				stmt:   stmt,
+				shared: len(call.Args) > 1,
+			})
+		}
+	}
+	return middleware
+}
+
+// routerCall decodes a <recv>.Router.<fn>(...) statement
+func (r *Router) routerCall(stmt ast.Stmt, recv string) (*ast.CallExpr, string, bool) {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil, "", false
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok {
+		return nil, "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || types.ExprString(sel.X) != recv+".Router" {
+		return nil, "", false
+	}
+	return call, sel.Sel.Name, true
+}
+
+// methodName decodes http.MethodGet or "GET"
+func methodName(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		for method, name := range methodConsts {
+			if types.ExprString(e) == "http."+name {
+				return method, true
+			}
+		}
+	case *ast.BasicLit:
+		if method, err := strconv.Unquote(e.Value); err == nil && ValidMethod(method) {
+			return method, true
+		}
+	}
+	return "", false
+}
+
+// HandlerVar returns the variable assigned the result of calling constructor,
+// e.g. pageHandler for pageHandler := pages.NewPages(...)
+func (r *Router) HandlerVar(constructor string) (string, bool) {
+	for _, stmt := range r.routes.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok || types.ExprString(call.Fun) != constructor {
+			continue
+		}
+		if ident, ok := assign.Lhs[0].(*ast.Ident); ok {
+			return ident.Name, true
+		}
+	}
+	return "", false
+}
+
+// HandlerVars lists the variables declared in RegisterRoutes, which route handlers refer to
+func (r *Router) HandlerVars() []string {
+	var vars []string
+	for _, stmt := range r.routes.Body.List {
+		if assign, ok := stmt.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+					vars = append(vars, ident.Name)
+				}
+			}
+		}
+	}
+	return vars
+}
+
+// usesIdent reports whether node refers to the identifier name
+func usesIdent(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -1,45 +1,76 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
-func ToCamelCase(s string) string {
-	parts := strings.FieldsFunc(s, func(r rune) bool {
+// words splits s at dashes, underscores and spaces, so "my-app_name" gives
+// [my app name]. Case changes do not start a word: MyApp stays a single word,
+// as project names were always converted that way.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
 		return r == '-' || r == '_' || r == ' '
 	})
+}
+
+// capitalize upper-cases the first letter of a lower-cased word
+func capitalize(word string) string {
+	word = strings.ToLower(word)
+	for i, r := range word {
+		return string(unicode.ToUpper(r)) + word[i+len(string(r)):]
+	}
+	return word
+}
 
+func ToCamelCase(s string) string {
+	parts := words(s)
 	if len(parts) == 0 {
 		return s
 	}
 
 	result := strings.ToLower(parts[0])
 	for i := 1; i < len(parts); i++ {
-		if len(parts[i]) > 0 {
-			result += strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
-		}
+		result += capitalize(parts[i])
 	}
 	return result
 }
 
-func ToSnakeCase(s string) string {
-	parts := strings.FieldsFunc(s, func(r rune) bool {
-		return r == '-' || r == '_' || r == ' '
-	})
+// ToPascalCase joins the words of s, each capitalized: my-app gives MyApp
+func ToPascalCase(s string) string {
+	parts := words(s)
+	for i, part := range parts {
+		parts[i] = capitalize(part)
+	}
+	return strings.Join(parts, "")
+}
 
+func ToSnakeCase(s string) string {
+	parts := words(s)
 	for i, part := range parts {
 		parts[i] = strings.ToLower(part)
 	}
-
 	return strings.Join(parts, "_")
 }
 
-func ToKebabCase(s string) string {
-	parts := strings.FieldsFunc(s, func(r rune) bool {
-		return r == '-' || r == '_' || r == ' '
-	})
+// ToScreamingSnakeCase joins the upper-cased words of s with underscores: my-app gives MY_APP
+func ToScreamingSnakeCase(s string) string {
+	return strings.ToUpper(ToSnakeCase(s))
+}
 
+func ToKebabCase(s string) string {
+	parts := words(s)
 	for i, part := range parts {
 		parts[i] = strings.ToLower(part)
 	}
-
 	return strings.Join(parts, "-")
 }
+
+// ToTitleCase joins the capitalized words of s with spaces: my-app gives My App
+func ToTitleCase(s string) string {
+	parts := words(s)
+	for i, part := range parts {
+		parts[i] = capitalize(part)
+	}
+	return strings.Join(parts, " ")
+}
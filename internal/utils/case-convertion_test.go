@@ -0,0 +1,61 @@
+package utils
+
+import "testing"
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		in                                 string
+		camel, pascal, snake, kebab, title string
+	}{
+		{"my-app", "myApp", "MyApp", "my_app", "my-app", "My App"},
+		{"my_app", "myApp", "MyApp", "my_app", "my-app", "My App"},
+		{"my app", "myApp", "MyApp", "my_app", "my-app", "My App"},
+		{"user-profile_settings", "userProfileSettings", "UserProfileSettings", "user_profile_settings", "user-profile-settings", "User Profile Settings"},
+		{"--my--app--", "myApp", "MyApp", "my_app", "my-app", "My App"},
+		{"v2-api", "v2Api", "V2Api", "v2_api", "v2-api", "V2 Api"},
+		{"", "", "", "", "", ""},
+		{"-", "-", "", "", "", ""},
+		// Case changes do not split words, so existing project names keep
+		// the derived values they were generated with
+		{"MyApp", "myapp", "Myapp", "myapp", "myapp", "Myapp"},
+		{"myHTTPServer", "myhttpserver", "Myhttpserver", "myhttpserver", "myhttpserver", "Myhttpserver"},
+		{"My-App", "myApp", "MyApp", "my_app", "my-app", "My App"},
+		// Other punctuation is kept
+		{"my.app", "my.app", "My.app", "my.app", "my.app", "My.app"},
+		{"café-bar", "caféBar", "CaféBar", "café_bar", "café-bar", "Café Bar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			for _, c := range []struct {
+				name      string
+				got, want string
+			}{
+				{"ToCamelCase", ToCamelCase(tt.in), tt.camel},
+				{"ToPascalCase", ToPascalCase(tt.in), tt.pascal},
+				{"ToSnakeCase", ToSnakeCase(tt.in), tt.snake},
+				{"ToKebabCase", ToKebabCase(tt.in), tt.kebab},
+				{"ToTitleCase", ToTitleCase(tt.in), tt.title},
+			} {
+				if c.got != c.want {
+					t.Errorf("%s(%q) = %q, want %q", c.name, tt.in, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestToScreamingSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"my-app":     "MY_APP",
+		"api_key":    "API_KEY",
+		"db name":    "DB_NAME",
+		"MyApp":      "MYAPP",
+		"":           "",
+		"session-id": "SESSION_ID",
+	} {
+		if got := ToScreamingSnakeCase(in); got != want {
+			t.Errorf("ToScreamingSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// irregularPlurals maps singular nouns whose plural does not follow the suffix rules
+var irregularPlurals = map[string]string{
+	"analysis":  "analyses",
+	"calorie":   "calories",
+	"child":     "children",
+	"cookie":    "cookies",
+	"crisis":    "crises",
+	"criterion": "criteria",
+	"echo":      "echoes",
+	"foot":      "feet",
+	"goose":     "geese",
+	"half":      "halves",
+	"hero":      "heroes",
+	"knife":     "knives",
+	"leaf":      "leaves",
+	"life":      "lives",
+	"man":       "men",
+	"mouse":     "mice",
+	"movie":     "movies",
+	"ox":        "oxen",
+	"person":    "people",
+	"pie":       "pies",
+	"potato":    "potatoes",
+	"quiz":      "quizzes",
+	"shelf":     "shelves",
+	"shoe":      "shoes",
+	"tomato":    "tomatoes",
+	"tie":       "ties",
+	"tooth":     "teeth",
+	"wife":      "wives",
+	"wolf":      "wolves",
+	"woman":     "women",
+}
+
+// irregularSingulars is the reverse of irregularPlurals
+var irregularSingulars = func() map[string]string {
+	singulars := make(map[string]string, len(irregularPlurals))
+	for singular, plural := range irregularPlurals {
+		singulars[plural] = singular
+	}
+	return singulars
+}()
+
+// uncountable nouns are the same in singular and plural
+var uncountable = map[string]bool{
+	"data":        true,
+	"equipment":   true,
+	"feedback":    true,
+	"fish":        true,
+	"information": true,
+	"metadata":    true,
+	"news":        true,
+	"series":      true,
+	"sheep":       true,
+	"species":     true,
+}
+
+// Pluralize returns the plural of the last word of s, keeping its case:
+// user gives users, user_category gives user_categories
+func Pluralize(s string) string {
+	return inflectLastWord(s, func(word string) string {
+		if plural, ok := irregularPlurals[word]; ok {
+			return plural
+		}
+
+		switch {
+		case strings.HasSuffix(word, "z") && len(word) > 2 && isVowel(word[len(word)-2]) && !isVowel(word[len(word)-3]):
+			// A single z after a short vowel is doubled: whiz gives whizzes
+			return word + "zes"
+		case hasAnySuffix(word, "s", "x", "z", "ch", "sh"):
+			return word + "es"
+		case strings.HasSuffix(word, "y") && len(word) > 1 && !isVowel(word[len(word)-2]):
+			return word[:len(word)-1] + "ies"
+		}
+		return word + "s"
+	})
+}
+
+// Singularize returns the singular of the last word of s, keeping its case:
+// users gives user, user_categories gives user_category
+func Singularize(s string) string {
+	return inflectLastWord(s, func(word string) string {
+		if singular, ok := irregularSingulars[word]; ok {
+			return singular
+		}
+
+		switch {
+		case strings.HasSuffix(word, "ies") && len(word) > 3:
+			return word[:len(word)-3] + "y"
+		case hasAnySuffix(word, "sses", "xes", "zzes", "ches", "shes"):
+			return word[:len(word)-2]
+		case strings.HasSuffix(word, "uses") && len(word) > 4 && !isVowel(word[len(word)-5]):
+			// statuses, buses
+			return word[:len(word)-2]
+		case hasAnySuffix(word, "ss", "us", "is"):
+			return word
+		case strings.HasSuffix(word, "s"):
+			return word[:len(word)-1]
+		}
+		return word
+	})
+}
+
+// inflectLastWord applies inflect to the lower-cased trailing letters of s and
+// restores their case
+func inflectLastWord(s string, inflect func(string) string) string {
+	start := len(s)
+	for start > 0 && isASCIILetter(s[start-1]) {
+		start--
+	}
+
+	prefix, word := s[:start], s[start:]
+	if word == "" {
+		return s
+	}
+
+	// Only the trailing word of camelCase input is inflected
+	for i := len(word) - 1; i > 0; i-- {
+		if unicode.IsUpper(rune(word[i])) && unicode.IsLower(rune(word[i-1])) {
+			prefix, word = prefix+word[:i], word[i:]
+			break
+		}
+	}
+
+	lower := strings.ToLower(word)
+	if uncountable[lower] {
+		return s
+	}
+
+	return prefix + matchCase(word, inflect(lower))
+}
+
+// matchCase gives inflected the case of word. Letters the two share keep the
+// case they had in word, and the new ending is upper case only when the
+// ending it replaced was: ID gives IDs, USERS gives USER and Person gives People.
+func matchCase(word, inflected string) string {
+	n := 0
+	for n < len(word) && n < len(inflected) && (word[n]|0x20) == inflected[n] {
+		n++
+	}
+
+	ending := inflected[n:]
+	if rest := word[n:]; rest != "" && rest == strings.ToUpper(rest) {
+		ending = strings.ToUpper(ending)
+	}
+	return word[:n] + ending
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+func isASCIILetter(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
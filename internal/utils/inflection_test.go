@@ -0,0 +1,77 @@
+package utils
+
+import "testing"
+
+func TestPluralize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"user", "users"},
+		{"post", "posts"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"box", "boxes"},
+		{"match", "matches"},
+		{"wish", "wishes"},
+		{"status", "statuses"},
+		{"buzz", "buzzes"},
+		{"quiz", "quizzes"},
+		{"whiz", "whizzes"},
+		{"waltz", "waltzes"},
+		{"tie", "ties"},
+		{"cookie", "cookies"},
+		{"person", "people"},
+		{"leaf", "leaves"},
+		{"data", "data"},
+		{"user_category", "user_categories"},
+		{"blog-post", "blog-posts"},
+		{"UserProfile", "UserProfiles"},
+		{"Person", "People"},
+		{"ID", "IDs"},
+		{"userID", "userIDs"},
+		{"URL", "URLs"},
+		{"", ""},
+		{"v2", "v2"},
+	}
+
+	for _, tt := range tests {
+		if got := Pluralize(tt.in); got != tt.want {
+			t.Errorf("Pluralize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"users", "user"},
+		{"categories", "category"},
+		{"days", "day"},
+		{"boxes", "box"},
+		{"matches", "match"},
+		{"wishes", "wish"},
+		{"statuses", "status"},
+		{"buses", "bus"},
+		{"buzzes", "buzz"},
+		{"quizzes", "quiz"},
+		{"ties", "tie"},
+		{"cookies", "cookie"},
+		{"movies", "movie"},
+		{"people", "person"},
+		{"leaves", "leaf"},
+		{"address", "address"},
+		{"status", "status"},
+		{"analysis", "analysis"},
+		{"news", "news"},
+		{"user_categories", "user_category"},
+		{"UserProfiles", "UserProfile"},
+		{"People", "Person"},
+		{"IDs", "ID"},
+		{"USERS", "USER"},
+		{"CATEGORIES", "CATEGORY"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Singularize(tt.in); got != tt.want {
+			t.Errorf("Singularize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/hex"
+	"regexp"
+	"testing"
+)
+
+func TestRandomSecret(t *testing.T) {
+	for _, n := range []int{1, 16, 32} {
+		secret, err := RandomSecret(n)
+		if err != nil {
+			t.Fatalf("RandomSecret(%d): %v", n, err)
+		}
+		if b, err := hex.DecodeString(secret); err != nil || len(b) != n {
+			t.Errorf("RandomSecret(%d) = %q, want %d hex encoded bytes", n, secret, n)
+		}
+	}
+
+	for _, n := range []int{0, -1} {
+		if _, err := RandomSecret(n); err == nil {
+			t.Errorf("RandomSecret(%d) succeeded, want an error", n)
+		}
+	}
+
+	a, _ := RandomSecret(32)
+	b, _ := RandomSecret(32)
+	if a == b {
+		t.Errorf("RandomSecret returned %q twice", a)
+	}
+}
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewUUID(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id, err := NewUUID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !uuidV4.MatchString(id) {
+			t.Fatalf("NewUUID() = %q, not a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewUUID returned %q twice", id)
+		}
+		seen[id] = true
+	}
+}
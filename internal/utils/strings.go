@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// Indent prefixes every non-empty line of s with n spaces
+func Indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ToGoIdentifier turns s into a valid, unexported Go identifier: my-app gives
+// myApp, 2fa gives _2fa and type gives type_
+func ToGoIdentifier(s string) string {
+	var b strings.Builder
+	for _, r := range ToCamelCase(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+
+	ident := b.String()
+	switch {
+	case ident == "":
+		return "_"
+	case unicode.IsDigit([]rune(ident)[0]):
+		return "_" + ident
+	case token.IsKeyword(ident):
+		return ident + "_"
+	}
+	return ident
+}
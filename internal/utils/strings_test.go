@@ -0,0 +1,42 @@
+package utils
+
+import "testing"
+
+func TestIndent(t *testing.T) {
+	tests := []struct {
+		n        int
+		in, want string
+	}{
+		{2, "a", "  a"},
+		{4, "a\nb", "    a\n    b"},
+		{2, "a\n\nb\n", "  a\n\n  b\n"},
+		{0, "a\nb", "a\nb"},
+		{2, "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Indent(tt.n, tt.in); got != tt.want {
+			t.Errorf("Indent(%d, %q) = %q, want %q", tt.n, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToGoIdentifier(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"my-app", "myApp"},
+		{"user_name", "userName"},
+		{"2fa", "_2fa"},
+		{"type", "type_"},
+		{"func", "func_"},
+		{"my.app", "myapp"},
+		{"café", "café"},
+		{"", "_"},
+		{"---", "_"},
+	}
+
+	for _, tt := range tests {
+		if got := ToGoIdentifier(tt.in); got != tt.want {
+			t.Errorf("ToGoIdentifier(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}